@@ -1,6 +1,26 @@
 package binding
 
-import "net/http"
+import (
+	"github.com/Jack-ZL/go_rookie/internal/grstrings"
+	"net/http"
+)
+
+// 常用的MIME类型
+const (
+	MIMEJSON              = "application/json"
+	MIMEXML               = "application/xml"
+	MIMEXML2              = "text/xml"
+	MIMEHTML              = "text/html"
+	MIMEPlain             = "text/plain"
+	MIMEPOSTForm          = "application/x-www-form-urlencoded"
+	MIMEMultipartPOSTForm = "multipart/form-data"
+	MIMEYAML              = "application/yaml"
+	MIMEYAML2             = "application/x-yaml"
+	MIMETOML              = "application/toml"
+	MIMEMsgPack           = "application/msgpack"
+	MIMEMsgPack2          = "application/x-msgpack"
+	MIMEProtoBuf          = "application/x-protobuf"
+)
 
 /**
  * Binding
@@ -12,6 +32,49 @@ type Binding interface {
 }
 
 var (
-	JSON = jsonBinding{}
-	XML  = xmlBinding{}
+	JSON          = jsonBinding{}
+	XML           = xmlBinding{}
+	Form          = formBinding{}
+	Query         = queryBinding{}
+	FormPost      = formPostBinding{}
+	FormMultipart = formMultipartBinding{}
+	Header        = headerBinding{}
+	Cookie        = cookieBinding{}
+	Uri           = uriBinding{}
+	YAML          = yamlBinding{}
+	TOML          = tomlBinding{}
+	MsgPack       = msgpackBinding{}
+	ProtoBuf      = protobufBinding{}
 )
+
+/**
+ * Default
+ * @Author：Jack-Z
+ * @Description: 根据请求方式和Content-Type选择绑定器，GET请求使用Form，无法识别时使用JSON
+ * @param method
+ * @param contentType 请求的Content-Type，可以带参数，如“application/json; charset=utf-8”
+ * @return Binding
+ */
+func Default(method, contentType string) Binding {
+	if method == http.MethodGet {
+		return Form
+	}
+	switch grstrings.MediaType(contentType) {
+	case MIMEXML, MIMEXML2:
+		return XML
+	case MIMEPOSTForm:
+		return Form
+	case MIMEMultipartPOSTForm:
+		return FormMultipart
+	case MIMEYAML, MIMEYAML2:
+		return YAML
+	case MIMETOML:
+		return TOML
+	case MIMEMsgPack, MIMEMsgPack2:
+		return MsgPack
+	case MIMEProtoBuf:
+		return ProtoBuf
+	default:
+		return JSON
+	}
+}
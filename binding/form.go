@@ -0,0 +1,84 @@
+package binding
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+)
+
+const defaultMemory = 32 << 20 // 解析multipart表单时最多使用32M内存，超出部分写入临时文件
+
+type formBinding struct{}
+type queryBinding struct{}
+type formPostBinding struct{}
+type formMultipartBinding struct{}
+
+func (formBinding) Name() string {
+	return "form"
+}
+
+/**
+ * Bind
+ * @Author：Jack-Z
+ * @Description: 绑定query参数和表单（urlencoded、multipart），同名时表单优先，
+ * multipart表单中的文件绑定到*multipart.FileHeader字段
+ * @receiver formBinding
+ * @param r
+ * @param data
+ * @return error
+ */
+func (formBinding) Bind(r *http.Request, data any) error {
+	if err := r.ParseMultipartForm(defaultMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+	var files map[string][]*multipart.FileHeader
+	if r.MultipartForm != nil {
+		files = r.MultipartForm.File
+	}
+	if err := mapFormWithFiles(data, r.Form, files, "form"); err != nil {
+		return err
+	}
+	return validate(data)
+}
+
+func (queryBinding) Name() string {
+	return "query"
+}
+
+// Bind 只绑定query参数
+func (queryBinding) Bind(r *http.Request, data any) error {
+	if err := mapForm(data, r.URL.Query(), "form"); err != nil {
+		return err
+	}
+	return validate(data)
+}
+
+func (formPostBinding) Name() string {
+	return "form-urlencoded"
+}
+
+// Bind 只绑定请求体中的urlencoded表单
+func (formPostBinding) Bind(r *http.Request, data any) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	if err := mapForm(data, r.PostForm, "form"); err != nil {
+		return err
+	}
+	return validate(data)
+}
+
+func (formMultipartBinding) Name() string {
+	return "multipart/form-data"
+}
+
+// Bind 只绑定请求体中的multipart表单，包括文件
+func (formMultipartBinding) Bind(r *http.Request, data any) error {
+	if err := r.ParseMultipartForm(defaultMemory); err != nil {
+		return err
+	}
+	if err := mapFormWithFiles(data, r.MultipartForm.Value, r.MultipartForm.File, "form"); err != nil {
+		return err
+	}
+	return validate(data)
+}
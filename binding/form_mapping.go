@@ -0,0 +1,401 @@
+package binding
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errUnknownType = errors.New("unknown type")
+	errNotPointer  = errors.New("binding element must be a pointer to struct")
+
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+)
+
+/**
+ * fieldSource
+ * @Description: 字段的取值来源，如query参数、表单、请求头
+ */
+type fieldSource interface {
+	values(key string) ([]string, bool)
+}
+
+type valuesSource map[string][]string
+
+func (s valuesSource) values(key string) ([]string, bool) {
+	v, ok := s[key]
+	return v, ok
+}
+
+// headerSource 请求头的key不区分大小写
+type headerSource map[string][]string
+
+func (s headerSource) values(key string) ([]string, bool) {
+	for k, v := range s {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+/**
+ * mapForm
+ * @Author：Jack-Z
+ * @Description: 按tag把values中的值填充到结构体，未设置tag的字段使用字段名，
+ * tag为“-”时跳过，tag中可以用default设置默认值，如 form:"page,default=1"，
+ * 切片的默认值用“;”分隔，如 form:"ids,default=1;2"
+ * @param ptr 结构体指针
+ * @param values
+ * @param tag 使用的tag，如form、header、uri、cookie
+ * @return error
+ */
+func mapForm(ptr any, values map[string][]string, tag string) error {
+	return mapFormWithFiles(ptr, values, nil, tag)
+}
+
+func mapFormWithFiles(ptr any, values map[string][]string, files map[string][]*multipart.FileHeader, tag string) error {
+	var source fieldSource = valuesSource(values)
+	if tag == "header" {
+		source = headerSource(values)
+	}
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errNotPointer
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errNotPointer
+	}
+	_, err := mapStruct(rv, source, files, tag, make(map[reflect.Type]bool))
+	return err
+}
+
+/**
+ * mapStruct
+ * @Author：Jack-Z
+ * @Description: 填充结构体的字段，嵌套的结构体递归填充；
+ * 自引用的类型（如 Next *Node）不再递归，为nil的嵌套指针只在有字段被赋值时才分配
+ * @param rv
+ * @param source
+ * @param files
+ * @param tag
+ * @param visiting 正在填充的结构体类型
+ * @return bool 是否有字段被赋值
+ * @return error
+ */
+func mapStruct(rv reflect.Value, source fieldSource, files map[string][]*multipart.FileHeader, tag string, visiting map[reflect.Type]bool) (bool, error) {
+	rt := rv.Type()
+	visiting[rt] = true
+	defer delete(visiting, rt)
+	set := false
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts := parseTag(field.Tag.Get(tag))
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+
+		// 嵌套的结构体（time.Time、multipart.FileHeader等可以直接赋值的类型除外）
+		if isNestedStruct(field.Type) {
+			ok, err := mapNested(fv, source, files, tag, visiting)
+			if err != nil {
+				return false, err
+			}
+			set = set || ok
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if isFileField(field.Type) {
+			if err := setFiles(fv, files[name]); err != nil {
+				return false, fmt.Errorf("field [%s]: %w", name, err)
+			}
+			set = set || len(files[name]) > 0
+			continue
+		}
+
+		vs, ok := source.values(name)
+		if !ok || len(vs) == 0 {
+			def, hasDefault := opts["default"]
+			if !hasDefault {
+				continue
+			}
+			if isSliceField(field.Type) {
+				vs = strings.Split(def, ";")
+			} else {
+				vs = []string{def}
+			}
+		}
+		if err := setField(fv, field, vs); err != nil {
+			return false, fmt.Errorf("field [%s]: %w", name, err)
+		}
+		set = true
+	}
+	return set, nil
+}
+
+// mapNested 填充嵌套的结构体字段，为nil的指针先填充到新值，有字段被赋值时再设置
+func mapNested(fv reflect.Value, source fieldSource, files map[string][]*multipart.FileHeader, tag string, visiting map[reflect.Type]bool) (bool, error) {
+	if fv.Kind() != reflect.Pointer {
+		return mapStruct(fv, source, files, tag, visiting)
+	}
+	if visiting[fv.Type().Elem()] {
+		return false, nil
+	}
+	if !fv.IsNil() {
+		return mapStruct(fv.Elem(), source, files, tag, visiting)
+	}
+	nv := reflect.New(fv.Type().Elem())
+	set, err := mapStruct(nv.Elem(), source, files, tag, visiting)
+	if set && err == nil {
+		fv.Set(nv)
+	}
+	return set, err
+}
+
+// parseTag 拆分tag，如“page,default=1”返回“page”和{"default": "1"}
+func parseTag(tag string) (string, map[string]string) {
+	name, rest, _ := strings.Cut(tag, ",")
+	opts := make(map[string]string)
+	for rest != "" {
+		var opt string
+		opt, rest, _ = strings.Cut(rest, ",")
+		key, value, _ := strings.Cut(opt, "=")
+		opts[strings.TrimSpace(key)] = value
+	}
+	return strings.TrimSpace(name), opts
+}
+
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType || t == fileHeaderType.Elem() {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func isFileField(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t == fileHeaderType || t == fileHeaderType.Elem()
+}
+
+func isSliceField(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice || t.Kind() == reflect.Array
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func setFiles(fv reflect.Value, files []*multipart.FileHeader) error {
+	if len(files) == 0 {
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.Pointer:
+		fv.Set(reflect.ValueOf(files[0]))
+	case reflect.Struct:
+		fv.Set(reflect.ValueOf(*files[0]))
+	case reflect.Slice:
+		slice := reflect.MakeSlice(fv.Type(), len(files), len(files))
+		for i, f := range files {
+			if err := setFiles(slice.Index(i), []*multipart.FileHeader{f}); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+	case reflect.Array:
+		if len(files) != fv.Len() {
+			return fmt.Errorf("expected %d files, got %d", fv.Len(), len(files))
+		}
+		for i, f := range files {
+			if err := setFiles(fv.Index(i), []*multipart.FileHeader{f}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/**
+ * setField
+ * @Author：Jack-Z
+ * @Description: 把字符串值转换后赋给字段，切片使用全部值，其他类型使用第一个值
+ * @param fv
+ * @param field 用于读取time_format等tag
+ * @param vs
+ * @return error
+ */
+func setField(fv reflect.Value, field reflect.StructField, vs []string) error {
+	switch fv.Kind() {
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 && !isSliceField(fv.Type().Elem()) {
+			// []byte按字符串处理
+			fv.SetBytes([]byte(vs[0]))
+			return nil
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(vs), len(vs))
+		for i, v := range vs {
+			if err := setValue(slice.Index(i), field, v); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	case reflect.Array:
+		if len(vs) != fv.Len() {
+			return fmt.Errorf("expected %d values, got %d", fv.Len(), len(vs))
+		}
+		for i, v := range vs {
+			if err := setValue(fv.Index(i), field, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return setValue(fv, field, vs[0])
+}
+
+func setValue(fv reflect.Value, field reflect.StructField, value string) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setValue(fv.Elem(), field, value)
+	}
+	if fv.Type() == timeType {
+		return setTime(fv, field, value)
+	}
+	if fv.CanAddr() {
+		if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+	}
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		if value == "" {
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value == "" {
+			value = "0"
+		}
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value == "" {
+			value = "0"
+		}
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if value == "" {
+			value = "0"
+		}
+		n, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Interface:
+		fv.Set(reflect.ValueOf(value))
+	default:
+		return errUnknownType
+	}
+	return nil
+}
+
+/**
+ * setTime
+ * @Author：Jack-Z
+ * @Description: 解析时间，time_format为布局（默认RFC3339），也可以是unix、unixmilli、unixnano，
+ * time_utc:"1"时使用UTC，time_location设置时区，如“Asia/Shanghai”
+ * @param fv
+ * @param field
+ * @param value
+ * @return error
+ */
+func setTime(fv reflect.Value, field reflect.StructField, value string) error {
+	if value == "" {
+		fv.Set(reflect.ValueOf(time.Time{}))
+		return nil
+	}
+	format := field.Tag.Get("time_format")
+	switch format {
+	case "unix", "unixmilli", "unixnano":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		var t time.Time
+		switch format {
+		case "unix":
+			t = time.Unix(n, 0)
+		case "unixmilli":
+			t = time.UnixMilli(n)
+		default:
+			t = time.Unix(0, n)
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	case "":
+		format = time.RFC3339
+	}
+
+	loc := time.Local
+	if utc, _ := strconv.ParseBool(field.Tag.Get("time_utc")); utc {
+		loc = time.UTC
+	}
+	if name := field.Tag.Get("time_location"); name != "" {
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return err
+		}
+		loc = l
+	}
+	t, err := time.ParseInLocation(format, value, loc)
+	if err != nil {
+		return err
+	}
+	fv.Set(reflect.ValueOf(t))
+	return nil
+}
@@ -0,0 +1,111 @@
+package binding
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type pageQuery struct {
+	Page    int           `form:"page,default=1"`
+	Size    int           `form:"size" validate:"max=100"`
+	Tags    []string      `form:"tag"`
+	IDs     []int         `form:"ids,default=1;2"`
+	Since   time.Time     `form:"since" time_format:"2006-01-02" time_utc:"1"`
+	Timeout time.Duration `form:"timeout"`
+	Active  *bool         `form:"active"`
+	Ignored string        `form:"-"`
+}
+
+func TestQueryBinding(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?size=20&tag=a&tag=b&since=2024-01-02&timeout=3s&active=true&Ignored=x", nil)
+	var q pageQuery
+	if err := Default(r.Method, "").Bind(r, &q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Page != 1 || q.Size != 20 || strings.Join(q.Tags, ",") != "a,b" || len(q.IDs) != 2 || q.IDs[1] != 2 {
+		t.Fatalf("unexpected result %+v", q)
+	}
+	if !q.Since.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) || q.Timeout != 3*time.Second || q.Active == nil || !*q.Active || q.Ignored != "" {
+		t.Fatalf("unexpected result %+v", q)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/?size=200", nil)
+	if err := Query.Bind(r, &q); err == nil {
+		t.Fatal("expected validation error")
+	}
+	r = httptest.NewRequest(http.MethodGet, "/?size=abc", nil)
+	if err := Query.Bind(r, &q); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestMultipartBinding(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("name", "avatar")
+	fw, _ := mw.CreateFormFile("file", "a.txt")
+	fw.Write([]byte("hello"))
+	mw.Close()
+	r := httptest.NewRequest(http.MethodPost, "/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var form struct {
+		Name string                `form:"name" validate:"required"`
+		File *multipart.FileHeader `form:"file"`
+	}
+	if err := Default(r.Method, r.Header.Get("Content-Type")).Bind(r, &form); err != nil {
+		t.Fatal(err)
+	}
+	if form.Name != "avatar" || form.File == nil || form.File.Filename != "a.txt" || form.File.Size != 5 {
+		t.Fatalf("unexpected result %+v", form)
+	}
+}
+
+func TestHeaderAndUriBinding(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Request-Id", "abc")
+	var h struct {
+		RequestID string `header:"x-request-id" validate:"required"`
+	}
+	if err := Header.Bind(r, &h); err != nil || h.RequestID != "abc" {
+		t.Fatalf("unexpected result %+v %v", h, err)
+	}
+
+	var u struct {
+		ID uint64 `uri:"id" validate:"required"`
+	}
+	if err := Uri.BindUri(map[string][]string{"id": {"42"}}, &u); err != nil || u.ID != 42 {
+		t.Fatalf("unexpected result %+v %v", u, err)
+	}
+	u.ID = 0
+	if err := Uri.BindUri(map[string][]string{}, &u); err == nil {
+		t.Fatal("expected validation error")
+	}
+}
+
+type treeQuery struct {
+	Name   string `form:"name"`
+	Next   *treeQuery
+	Filter *struct {
+		Status string `form:"status"`
+	}
+	Paging *struct {
+		Limit int `form:"limit"`
+	}
+}
+
+func TestNestedBinding(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?name=root&status=open", nil)
+	var q treeQuery
+	if err := Query.Bind(r, &q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Name != "root" || q.Next != nil || q.Filter == nil || q.Filter.Status != "open" || q.Paging != nil {
+		t.Fatalf("unexpected result %+v", q)
+	}
+}
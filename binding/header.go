@@ -0,0 +1,35 @@
+package binding
+
+import "net/http"
+
+type headerBinding struct{}
+
+func (headerBinding) Name() string {
+	return "header"
+}
+
+// Bind 按header tag绑定请求头，请求头名称不区分大小写
+func (headerBinding) Bind(r *http.Request, data any) error {
+	if err := mapForm(data, r.Header, "header"); err != nil {
+		return err
+	}
+	return validate(data)
+}
+
+type cookieBinding struct{}
+
+func (cookieBinding) Name() string {
+	return "cookie"
+}
+
+// Bind 按cookie tag绑定cookie，同名cookie绑定到切片字段
+func (cookieBinding) Bind(r *http.Request, data any) error {
+	values := make(map[string][]string)
+	for _, cookie := range r.Cookies() {
+		values[cookie.Name] = append(values[cookie.Name], cookie.Value)
+	}
+	if err := mapForm(data, values, "cookie"); err != nil {
+		return err
+	}
+	return validate(data)
+}
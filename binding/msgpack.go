@@ -0,0 +1,23 @@
+package binding
+
+import (
+	"errors"
+	"github.com/vmihailenco/msgpack/v5"
+	"net/http"
+)
+
+type msgpackBinding struct{}
+
+func (msgpackBinding) Name() string {
+	return "msgpack"
+}
+
+func (msgpackBinding) Bind(r *http.Request, data any) error {
+	if r.Body == nil {
+		return errors.New("invalid request")
+	}
+	if err := msgpack.NewDecoder(r.Body).Decode(data); err != nil {
+		return err
+	}
+	return validate(data)
+}
@@ -0,0 +1,41 @@
+package binding
+
+import (
+	"errors"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+)
+
+type protobufBinding struct{}
+
+func (protobufBinding) Name() string {
+	return "protobuf"
+}
+
+/**
+ * Bind
+ * @Author：Jack-Z
+ * @Description: 解析protobuf请求体，data需要实现proto.Message
+ * @receiver protobufBinding
+ * @param r
+ * @param data
+ * @return error
+ */
+func (protobufBinding) Bind(r *http.Request, data any) error {
+	if r.Body == nil {
+		return errors.New("invalid request")
+	}
+	message, ok := data.(proto.Message)
+	if !ok {
+		return errors.New("protobuf binding: data is not a proto.Message")
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if err := proto.Unmarshal(body, message); err != nil {
+		return err
+	}
+	return validate(data)
+}
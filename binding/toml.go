@@ -0,0 +1,23 @@
+package binding
+
+import (
+	"errors"
+	"github.com/BurntSushi/toml"
+	"net/http"
+)
+
+type tomlBinding struct{}
+
+func (tomlBinding) Name() string {
+	return "toml"
+}
+
+func (tomlBinding) Bind(r *http.Request, data any) error {
+	if r.Body == nil {
+		return errors.New("invalid request")
+	}
+	if _, err := toml.NewDecoder(r.Body).Decode(data); err != nil {
+		return err
+	}
+	return validate(data)
+}
@@ -0,0 +1,24 @@
+package binding
+
+/**
+ * BindingUri
+ * @Description: 路径参数绑定接口，路径参数不在http.Request中，由调用方传入
+ */
+type BindingUri interface {
+	Name() string
+	BindUri(map[string][]string, any) error
+}
+
+type uriBinding struct{}
+
+func (uriBinding) Name() string {
+	return "uri"
+}
+
+// BindUri 按uri tag绑定路径参数，如路由“/user/:id”对应 uri:"id"
+func (uriBinding) BindUri(params map[string][]string, data any) error {
+	if err := mapForm(data, params, "uri"); err != nil {
+		return err
+	}
+	return validate(data)
+}
@@ -0,0 +1,23 @@
+package binding
+
+import (
+	"errors"
+	"gopkg.in/yaml.v3"
+	"net/http"
+)
+
+type yamlBinding struct{}
+
+func (yamlBinding) Name() string {
+	return "yaml"
+}
+
+func (yamlBinding) Bind(r *http.Request, data any) error {
+	if r.Body == nil {
+		return errors.New("invalid request")
+	}
+	if err := yaml.NewDecoder(r.Body).Decode(data); err != nil {
+		return err
+	}
+	return validate(data)
+}
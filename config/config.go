@@ -9,6 +9,7 @@ import (
 	"github.com/BurntSushi/toml"
 	grLog "github.com/Jack-ZL/go_rookie/log"
 	"os"
+	"strings"
 )
 
 var Conf = &GrConfig{
@@ -45,8 +46,9 @@ func init() {
  * @Description: 加载配置文件
  */
 func loadToml() {
+	// 同时注册到flag.CommandLine，应用自己调用flag.Parse时可以识别-conf
 	confFile := flag.String("conf", "conf/app.toml", "app config file")
-	flag.Parse()
+	*confFile = parseConfFlag(os.Args[1:], *confFile)
 	if _, err := os.Stat(*confFile); err != nil {
 		Conf.logger.Info("conf/app.toml file not load，because not exist")
 		return
@@ -58,3 +60,33 @@ func loadToml() {
 		return
 	}
 }
+
+/**
+ * parseConfFlag
+ * @Author：Jack-Z
+ * @Description: 直接扫描命令行参数取出-conf，支持“-conf x”、“--conf x”、“-conf=x”、“--conf=x”，
+ * 多次出现时使用最后一个，遇到“--”时停止。init时应用（以及go test）的参数还没有注册，不能调用flag.Parse
+ * @param args 命令行参数，不含程序名
+ * @param def 默认值
+ * @return string
+ */
+func parseConfFlag(args []string, def string) string {
+	confFile := def
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if name == arg {
+			continue
+		}
+		if strings.HasPrefix(name, "conf=") {
+			confFile = strings.TrimPrefix(name, "conf=")
+		} else if name == "conf" && i+1 < len(args) {
+			i++
+			confFile = args[i]
+		}
+	}
+	return confFile
+}
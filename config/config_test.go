@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestParseConfFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "conf/app.toml"},
+		{[]string{"-conf", "a.toml"}, "a.toml"},
+		{[]string{"--conf", "a.toml"}, "a.toml"},
+		{[]string{"-conf=a.toml"}, "a.toml"},
+		{[]string{"--conf=a.toml"}, "a.toml"},
+		{[]string{"-port", "8080", "-conf", "a.toml"}, "a.toml"},
+		{[]string{"-test.run", "TestX", "-test.v", "--conf=a.toml"}, "a.toml"},
+		{[]string{"serve", "-conf", "a.toml"}, "a.toml"},
+		{[]string{"-conf", "a.toml", "-conf=b.toml"}, "b.toml"},
+		{[]string{"-config", "a.toml", "-conference=b"}, "conf/app.toml"},
+		{[]string{"-conf"}, "conf/app.toml"},
+		{[]string{"--", "-conf", "a.toml"}, "conf/app.toml"},
+	}
+	for _, tt := range tests {
+		if got := parseConfFlag(tt.args, "conf/app.toml"); got != tt.want {
+			t.Errorf("%q: want %q, got %q", tt.args, tt.want, got)
+		}
+	}
+}
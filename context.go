@@ -9,29 +9,128 @@ import (
 	"html/template"
 	"io"
 	"log"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
+	"strconv"
 	"strings"
 	"sync"
 )
 
 const defaultMultipartMemory = 32 << 20 // 默认是分配32M的内存
 
+const abortIndex = math.MaxInt16 // 处理链被中断时的下标
+
 type Context struct {
-	W                     http.ResponseWriter
+	W                     ResponseWriter // 响应，记录状态码和响应大小，原始的http.ResponseWriter可通过W.Unwrap()获取
+	writer                responseWriter
 	R                     *http.Request
 	engine                *Engine
 	queryCache            url.Values
 	formCache             url.Values
 	DisallowUnknownFields bool
 	IsValidate            bool
-	StatusCode            int
+	StatusCode            int // Deprecated: 只在Render中设置，使用W.Status()获取实际的状态码
 	Logger                *grLog.Logger
 	Keys                  map[string]any
+	Params                map[string]string // 路由匹配到的路径参数，如“/user/:id”中的id
+	HostParams            map[string]string // 域名规则中捕获的参数，如“{tenant}.example.com”中的tenant
 	mu                    sync.RWMutex
 	sameSite              http.SameSite // 降低跨域信息泄露的风险，并为跨站点请求伪造攻击提供一些保护
+	handlers              []HandlerFunc // 当前请求的处理链
+	index                 int           // 当前执行到的处理函数下标
+}
+
+/**
+ * reset
+ * @Author：Jack-Z
+ * @Description: Context从pool中复用，处理新请求前清空上一个请求的数据
+ * @receiver c
+ * @param w
+ * @param r
+ */
+func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
+	c.writer.reset(w)
+	c.W = &c.writer
+	c.R = r
+	c.queryCache = nil
+	c.formCache = nil
+	c.DisallowUnknownFields = false
+	c.IsValidate = false
+	c.StatusCode = 0
+	c.Keys = nil
+	c.Params = nil
+	c.HostParams = nil
+	c.sameSite = http.SameSiteDefaultMode
+	c.handlers = nil
+	c.index = -1
+}
+
+// handle 执行处理链
+func (c *Context) handle(handlers []HandlerFunc) {
+	c.handlers = handlers
+	c.index = -1
+	c.Next()
+}
+
+/**
+ * Next
+ * @Author：Jack-Z
+ * @Description: 在中间件中执行处理链中剩余的处理函数，返回后可以继续执行后置逻辑
+ * @receiver c
+ */
+func (c *Context) Next() {
+	c.index++
+	for c.index < len(c.handlers) {
+		c.handlers[c.index](c)
+		c.index++
+	}
+}
+
+/**
+ * Abort
+ * @Author：Jack-Z
+ * @Description: 中断处理链，剩余的处理函数不再执行，不影响当前函数继续执行
+ * @receiver c
+ */
+func (c *Context) Abort() {
+	c.index = abortIndex
+}
+
+/**
+ * AbortWithStatus
+ * @Author：Jack-Z
+ * @Description: 写入状态码并中断处理链
+ * @receiver c
+ * @param code
+ */
+func (c *Context) AbortWithStatus(code int) {
+	c.Abort()
+	c.W.WriteHeader(code)
+	c.W.WriteHeaderNow()
+	c.StatusCode = code
+}
+
+/**
+ * AbortWithStatusJSON
+ * @Author：Jack-Z
+ * @Description: 渲染json数据并中断处理链
+ * @receiver c
+ * @param code
+ * @param data
+ * @return error
+ */
+func (c *Context) AbortWithStatusJSON(code int, data any) error {
+	c.Abort()
+	return c.JSON(code, data)
+}
+
+// IsAborted 处理链是否已被中断
+func (c *Context) IsAborted() bool {
+	return c.index >= abortIndex
 }
 
 func (c *Context) SetSameSite(s http.SameSite) {
@@ -59,6 +158,156 @@ func (c *Context) SetBasicAuth(username, password string) {
 	c.R.Header.Set("Authorization", "GOROOKIE"+BasicAuth(username, password))
 }
 
+// 处理路径参数，比如：路由“/user/:id”匹配“/user/12”时，id=12
+
+/**
+ * GetParam
+ * @Author：Jack-Z
+ * @Description: 获取路径参数
+ * @receiver c
+ * @param key 参数名，“:id”对应“id”，“*”和“**”对应自身
+ * @return string
+ * @return bool
+ */
+func (c *Context) GetParam(key string) (string, bool) {
+	value, ok := c.Params[key]
+	return value, ok
+}
+
+/**
+ * Param
+ * @Author：Jack-Z
+ * @Description: 获取路径参数，不存在时返回空字符串
+ * @receiver c
+ * @param key
+ * @return string
+ */
+func (c *Context) Param(key string) string {
+	value, _ := c.GetParam(key)
+	return value
+}
+
+/**
+ * DefaultParam
+ * @Author：Jack-Z
+ * @Description: 获取路径参数，没有或为空 就用默认值
+ * @receiver c
+ * @param key
+ * @param defaultValue
+ * @return string
+ */
+func (c *Context) DefaultParam(key, defaultValue string) string {
+	if value, ok := c.GetParam(key); ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+/**
+ * ParamInt
+ * @Author：Jack-Z
+ * @Description: 获取int类型的路径参数
+ * @receiver c
+ * @param key
+ * @return int
+ * @return error 参数不存在或格式不正确
+ */
+func (c *Context) ParamInt(key string) (int, error) {
+	value, err := c.mustParam(key)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(value)
+}
+
+/**
+ * ParamInt64
+ * @Author：Jack-Z
+ * @Description: 获取int64类型的路径参数
+ * @receiver c
+ * @param key
+ * @return int64
+ * @return error
+ */
+func (c *Context) ParamInt64(key string) (int64, error) {
+	value, err := c.mustParam(key)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+/**
+ * ParamUint64
+ * @Author：Jack-Z
+ * @Description: 获取uint64类型的路径参数
+ * @receiver c
+ * @param key
+ * @return uint64
+ * @return error
+ */
+func (c *Context) ParamUint64(key string) (uint64, error) {
+	value, err := c.mustParam(key)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+/**
+ * ParamFloat64
+ * @Author：Jack-Z
+ * @Description: 获取float64类型的路径参数
+ * @receiver c
+ * @param key
+ * @return float64
+ * @return error
+ */
+func (c *Context) ParamFloat64(key string) (float64, error) {
+	value, err := c.mustParam(key)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+/**
+ * ParamBool
+ * @Author：Jack-Z
+ * @Description: 获取bool类型的路径参数
+ * @receiver c
+ * @param key
+ * @return bool
+ * @return error
+ */
+func (c *Context) ParamBool(key string) (bool, error) {
+	value, err := c.mustParam(key)
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(value)
+}
+
+/**
+ * HostParam
+ * @Author：Jack-Z
+ * @Description: 获取域名参数，见Engine.Host
+ * @receiver c
+ * @param key 参数名，“{tenant}”对应“tenant”，“*”对应自身
+ * @return string
+ */
+func (c *Context) HostParam(key string) string {
+	return c.HostParams[key]
+}
+
+func (c *Context) mustParam(key string) (string, error) {
+	value, ok := c.GetParam(key)
+	if !ok {
+		return "", fmt.Errorf("param [%s] not found", key)
+	}
+	return value, nil
+}
+
 // 处理query参数，比如：http://xxx.com/user/add?id=1&age=20&username=张三
 
 /**
@@ -457,32 +706,139 @@ func (c *Context) XML(status int, data any) error {
 	})
 }
 
+/**
+ * JSONP
+ * @Author：Jack-Z
+ * @Description: 按query参数callback输出JSONP，没有callback时输出普通json，
+ * callback不合法时响应400并返回render.ErrInvalidCallback
+ * @receiver c
+ * @param status
+ * @param data
+ * @return error
+ */
+func (c *Context) JSONP(status int, data any) error {
+	err := c.Render(status, &render.JSONP{
+		Callback: c.GetQuery("callback"),
+		Data:     data,
+	})
+	if errors.Is(err, render.ErrInvalidCallback) {
+		c.W.WriteHeader(http.StatusBadRequest)
+		c.StatusCode = http.StatusBadRequest
+	}
+	return err
+}
+
+// SecureJSON 在json前加上Engine.SecureJSONPrefix，防止json劫持
+func (c *Context) SecureJSON(status int, data any) error {
+	return c.Render(status, &render.SecureJSON{
+		Prefix: c.engine.SecureJSONPrefix,
+		Data:   data,
+	})
+}
+
+// AsciiJSON 非ASCII字符转义为\uXXXX的json
+func (c *Context) AsciiJSON(status int, data any) error {
+	return c.Render(status, &render.AsciiJSON{
+		Data: data,
+	})
+}
+
+// PureJSON 不转义html字符的json
+func (c *Context) PureJSON(status int, data any) error {
+	return c.Render(status, &render.PureJSON{
+		Data: data,
+	})
+}
+
+// IndentedJSON 缩进格式的json，只建议在调试时使用
+func (c *Context) IndentedJSON(status int, data any) error {
+	return c.Render(status, &render.IndentedJSON{
+		Data: data,
+	})
+}
+
+/**
+ * JSONStream
+ * @Author：Jack-Z
+ * @Description: 逐个元素输出json数组，用于导出大量数据，客户端断开连接时停止读取channel并返回render.ErrStreamInterrupted
+ * @receiver c
+ * @param status
+ * @param data 切片、数组或可接收的channel
+ * @return error
+ */
+func (c *Context) JSONStream(status int, data any) error {
+	return c.Render(status, &render.JSONStream{
+		Data: data,
+		Done: c.R.Context().Done(),
+	})
+}
+
+// YAML yaml数据渲染
+func (c *Context) YAML(status int, data any) error {
+	return c.Render(status, &render.YAML{
+		Data: data,
+	})
+}
+
+// TOML toml数据渲染，data需要是结构体或map
+func (c *Context) TOML(status int, data any) error {
+	return c.Render(status, &render.TOML{
+		Data: data,
+	})
+}
+
+// MsgPack MessagePack数据渲染
+func (c *Context) MsgPack(status int, data any) error {
+	return c.Render(status, &render.MsgPack{
+		Data: data,
+	})
+}
+
+// ProtoBuf protobuf数据渲染，data需要实现proto.Message
+func (c *Context) ProtoBuf(status int, data any) error {
+	return c.Render(status, &render.ProtoBuf{
+		Data: data,
+	})
+}
+
 /**
  * File
  * @Author：Jack-Z
- * @Description: 文件下载
+ * @Description: 文件下载，支持ETag、条件请求和Range请求（断点续传）
  * @receiver c
  * @param filename
  */
 func (c *Context) File(filename string) {
-	http.ServeFile(c.W, c.R, filename)
+	f, err := os.Open(filename)
+	if err != nil {
+		c.fileError(err)
+		return
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		c.fileError(err)
+		return
+	}
+	if stat.IsDir() {
+		// 目录由http.ServeFile处理（默认文件、目录列表）
+		http.ServeFile(c.W, c.R, filename)
+		return
+	}
+	c.serveFile(f, stat)
 }
 
 /**
  * FileAttachment
  * @Author：Jack-Z
- * @Description: 文件下载（可以自定义名称）
+ * @Description: 文件下载（可以自定义名称），Content-Disposition按RFC 6266设置，支持UTF-8文件名
  * @receiver c
  * @param filepath
  * @param filename
  */
 func (c *Context) FileAttachment(filepath, filename string) {
-	if isASCII(filename) {
-		c.W.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
-	} else {
-		c.W.Header().Set("Content-Disposition", `attachment; filename*=UTF-8''`+url.QueryEscape(filename))
-	}
-	http.ServeFile(c.W, c.R, filepath)
+	c.W.Header().Set("Content-Disposition", contentDisposition("attachment", filename))
+	c.File(filepath)
 }
 
 /**
@@ -494,12 +850,74 @@ func (c *Context) FileAttachment(filepath, filename string) {
  * @param fs
  */
 func (c *Context) FileFromFS(filepath string, fs http.FileSystem) {
-	defer func(old string) {
-		c.R.URL.Path = old
-	}(c.R.URL.Path)
+	if containsDotDot(filepath) {
+		c.String(http.StatusBadRequest, "invalid path")
+		return
+	}
+	f, err := fs.Open(path.Clean("/" + filepath))
+	if err != nil {
+		c.fileError(err)
+		return
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		c.fileError(err)
+		return
+	}
+	if stat.IsDir() {
+		defer func(old string) {
+			c.R.URL.Path = old
+		}(c.R.URL.Path)
+		c.R.URL.Path = filepath
+		http.FileServer(fs).ServeHTTP(c.W, c.R)
+		return
+	}
+	c.serveFile(f, stat)
+}
 
-	c.R.URL.Path = filepath
-	http.FileServer(fs).ServeHTTP(c.W, c.R)
+/**
+ * DataFromReader
+ * @Author：Jack-Z
+ * @Description: 流式输出reader中的数据。status为200且长度已知时支持Range请求和条件请求，
+ * 可以通过extraHeaders设置ETag（强ETag才能用于If-Range）和Last-Modified以支持断点续传；
+ * reader实现io.Seeker时可以任意seek，否则多个Range需要按升序排列
+ * @receiver c
+ * @param status
+ * @param contentLength 小于0表示长度未知
+ * @param contentType
+ * @param reader
+ * @param extraHeaders 额外的响应头，如Content-Disposition、ETag
+ * @return error
+ */
+func (c *Context) DataFromReader(status int, contentLength int64, contentType string, reader io.Reader, extraHeaders map[string]string) error {
+	header := c.W.Header()
+	for k, v := range extraHeaders {
+		header.Set(k, v)
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+
+	if status == http.StatusOK && contentLength >= 0 {
+		seeker, ok := reader.(io.ReadSeeker)
+		if !ok {
+			seeker = &forwardSeeker{r: reader, size: contentLength}
+		}
+		modTime, _ := http.ParseTime(header.Get("Last-Modified"))
+		// ServeContent会按If-Modified-Since等重新设置Last-Modified
+		header.Del("Last-Modified")
+		http.ServeContent(c.W, c.R, "", modTime, seeker)
+		return nil
+	}
+
+	if contentLength >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(contentLength, 10))
+	}
+	c.W.WriteHeader(status)
+	_, err := io.Copy(c.W, reader)
+	return err
 }
 
 /**
@@ -592,14 +1010,29 @@ func (c *Context) MustBindWith(obj any, bind binding.Binding) error {
 /**
  * ShouldBind
  * @Author：Jack-Z
- * @Description: json绑定器实现参数校验
+ * @Description: 使用绑定器实现参数校验，不传绑定器时根据请求的Content-Type选择
  * @receiver c
  * @param obj
  * @param bind
  * @return error
  */
-func (c *Context) ShouldBind(obj any, bind binding.Binding) error {
-	return bind.Bind(c.R, obj)
+func (c *Context) ShouldBind(obj any, bind ...binding.Binding) error {
+	if len(bind) == 0 {
+		return binding.Default(c.R.Method, c.ContentType()).Bind(c.R, obj)
+	}
+	return bind[0].Bind(c.R, obj)
+}
+
+/**
+ * Bind
+ * @Author：Jack-Z
+ * @Description: 根据请求的Content-Type选择绑定器，绑定失败时响应400
+ * @receiver c
+ * @param obj
+ * @return error
+ */
+func (c *Context) Bind(obj any) error {
+	return c.MustBindWith(obj, binding.Default(c.R.Method, c.ContentType()))
 }
 
 /**
@@ -615,6 +1048,78 @@ func (c *Context) BindXML(obj any) error {
 	return c.MustBindWith(obj, json)
 }
 
+// BindYAML 通过yaml绑定器实现参数校验
+func (c *Context) BindYAML(obj any) error {
+	return c.MustBindWith(obj, binding.YAML)
+}
+
+// BindTOML 通过toml绑定器实现参数校验
+func (c *Context) BindTOML(obj any) error {
+	return c.MustBindWith(obj, binding.TOML)
+}
+
+// BindMsgPack 通过MessagePack绑定器实现参数校验
+func (c *Context) BindMsgPack(obj any) error {
+	return c.MustBindWith(obj, binding.MsgPack)
+}
+
+// BindProtoBuf 通过protobuf绑定器实现参数校验，obj需要实现proto.Message
+func (c *Context) BindProtoBuf(obj any) error {
+	return c.MustBindWith(obj, binding.ProtoBuf)
+}
+
+// ShouldBindQuery 按form tag绑定query参数
+func (c *Context) ShouldBindQuery(obj any) error {
+	return c.ShouldBind(obj, binding.Query)
+}
+
+// ShouldBindForm 按form tag绑定query参数和表单，包括multipart表单中的文件
+func (c *Context) ShouldBindForm(obj any) error {
+	return c.ShouldBind(obj, binding.Form)
+}
+
+// ShouldBindHeader 按header tag绑定请求头
+func (c *Context) ShouldBindHeader(obj any) error {
+	return c.ShouldBind(obj, binding.Header)
+}
+
+// ShouldBindCookie 按cookie tag绑定cookie
+func (c *Context) ShouldBindCookie(obj any) error {
+	return c.ShouldBind(obj, binding.Cookie)
+}
+
+/**
+ * ShouldBindUri
+ * @Author：Jack-Z
+ * @Description: 按uri tag绑定路径参数
+ * @receiver c
+ * @param obj
+ * @return error
+ */
+func (c *Context) ShouldBindUri(obj any) error {
+	params := make(map[string][]string, len(c.Params))
+	for k, v := range c.Params {
+		params[k] = []string{v}
+	}
+	return binding.Uri.BindUri(params, obj)
+}
+
+/**
+ * BindUri
+ * @Author：Jack-Z
+ * @Description: 按uri tag绑定路径参数，绑定失败时响应400
+ * @receiver c
+ * @param obj
+ * @return error
+ */
+func (c *Context) BindUri(obj any) error {
+	if err := c.ShouldBindUri(obj); err != nil {
+		c.W.WriteHeader(http.StatusBadRequest)
+		return err
+	}
+	return nil
+}
+
 func (c *Context) Fail(code int, msg string) {
 	c.String(code, msg)
 }
@@ -642,12 +1147,16 @@ func (c *Context) HandlerWithError(statusCode int, obj any, err error) {
  * @param httpOnly
  */
 func (c *Context) SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
+	c.setCookie(name, url.QueryEscape(value), maxAge, path, domain, secure, httpOnly)
+}
+
+func (c *Context) setCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
 	if path == "" {
 		path = "/"
 	}
 	http.SetCookie(c.W, &http.Cookie{
 		Name:     name,
-		Value:    url.QueryEscape(value),
+		Value:    value,
 		MaxAge:   maxAge,
 		Path:     path,
 		Domain:   domain,
@@ -0,0 +1,141 @@
+package go_rookie
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Context实现context.Context，可以直接传给数据库、rpc客户端等需要context.Context的地方，
+// 截止时间和取消信号来自请求的context，客户端断开连接或请求结束时Done被关闭
+var _ context.Context = (*Context)(nil)
+
+/**
+ * Deadline
+ * @Author：Jack-Z
+ * @Description: 请求context的截止时间
+ * @receiver c
+ * @return time.Time
+ * @return bool
+ */
+func (c *Context) Deadline() (time.Time, bool) {
+	if c.R == nil {
+		return time.Time{}, false
+	}
+	return c.R.Context().Deadline()
+}
+
+/**
+ * Done
+ * @Author：Jack-Z
+ * @Description: 请求被取消或处理结束时关闭
+ * @receiver c
+ * @return <-chan struct{}
+ */
+func (c *Context) Done() <-chan struct{} {
+	if c.R == nil {
+		return nil
+	}
+	return c.R.Context().Done()
+}
+
+/**
+ * Err
+ * @Author：Jack-Z
+ * @Description: Done关闭后返回context.Canceled或context.DeadlineExceeded
+ * @receiver c
+ * @return error
+ */
+func (c *Context) Err() error {
+	if c.R == nil {
+		return nil
+	}
+	return c.R.Context().Err()
+}
+
+/**
+ * Value
+ * @Author：Jack-Z
+ * @Description: key为string时先从Keys中查找，找不到时再从请求的context中查找
+ * @receiver c
+ * @param key
+ * @return any
+ */
+func (c *Context) Value(key any) any {
+	if k, ok := key.(string); ok {
+		if value, exists := c.Get(k); exists {
+			return value
+		}
+	}
+	if c.R == nil {
+		return nil
+	}
+	return c.R.Context().Value(key)
+}
+
+/**
+ * Copy
+ * @Author：Jack-Z
+ * @Description: 复制一份可以在goroutine中使用的Context。Context处理完成后会放回pool复用，
+ * 在goroutine中必须使用副本；副本只能读取请求数据和Keys，不能写响应、不能执行处理链
+ * @receiver c
+ * @return *Context
+ */
+func (c *Context) Copy() *Context {
+	cp := &Context{
+		R:                     c.R,
+		engine:                c.engine,
+		DisallowUnknownFields: c.DisallowUnknownFields,
+		IsValidate:            c.IsValidate,
+		StatusCode:            c.StatusCode,
+		Logger:                c.Logger,
+		sameSite:              c.sameSite,
+		index:                 abortIndex,
+	}
+	// 副本不能写响应，只保留状态码等信息，写入时返回错误
+	cp.writer = c.writer
+	cp.writer.ResponseWriter = &copyWriter{header: c.W.Header().Clone()}
+	cp.writer.beforeFuncs = nil
+	cp.W = &cp.writer
+
+	c.mu.RLock()
+	if c.Keys != nil {
+		cp.Keys = make(map[string]any, len(c.Keys))
+		for k, v := range c.Keys {
+			cp.Keys[k] = v
+		}
+	}
+	c.mu.RUnlock()
+	cp.Params = copyStringMap(c.Params)
+	cp.HostParams = copyStringMap(c.HostParams)
+	return cp
+}
+
+var errCopiedContext = errors.New("can not write response with a copied context")
+
+// copyWriter Context副本使用的http.ResponseWriter，写入直接返回错误
+type copyWriter struct {
+	header http.Header
+}
+
+func (w *copyWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *copyWriter) Write([]byte) (int, error) {
+	return 0, errCopiedContext
+}
+
+func (w *copyWriter) WriteHeader(int) {}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	cp := make(map[string]string, len(m))
+	for k, v := range m {
+		cp[k] = v
+	}
+	return cp
+}
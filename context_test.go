@@ -0,0 +1,66 @@
+package go_rookie_test
+
+import (
+	"errors"
+	"github.com/Jack-ZL/go_rookie/gorookietest"
+	"github.com/Jack-ZL/go_rookie/render"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextCopy(t *testing.T) {
+	ctx, w := gorookietest.NewContext(nil, httptest.NewRequest("GET", "/user/1", nil))
+	ctx.Set("user", "jack")
+	ctx.Params = map[string]string{"id": "1"}
+	cp := ctx.Copy()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if v, ok := cp.Get("user"); !ok || v != "jack" || cp.Param("id") != "1" {
+			t.Errorf("unexpected copy %v %q", v, cp.Param("id"))
+		}
+		// 副本写响应时返回错误，不影响原响应
+		cp.W.Header().Set("X-Copy", "1")
+		if _, err := cp.W.Write([]byte("from copy")); err == nil {
+			t.Error("expected write error on copied context")
+		}
+		if _, _, err := cp.W.Hijack(); err == nil {
+			t.Error("expected hijack error on copied context")
+		}
+		cp.W.Flush()
+	}()
+	<-done
+
+	ctx.Set("user", "rose")
+	if v, _ := cp.Get("user"); v != "jack" {
+		t.Errorf("copy shares keys with the original: %v", v)
+	}
+	ctx.String(200, "ok")
+	if w.Body.String() != "ok" || w.Header().Get("X-Copy") != "" {
+		t.Errorf("unexpected response %q %v", w.Body.String(), w.Header())
+	}
+}
+
+func TestContextJSONP(t *testing.T) {
+	tests := []struct {
+		query  string
+		status int
+		body   string
+		err    error
+	}{
+		{"", http.StatusOK, "[1]", nil},
+		{"?callback=app.cb", http.StatusOK, "/**/ typeof app.cb === 'function' && app.cb([1]);", nil},
+		// callback不合法时响应400，不输出内容
+		{"?callback=alert%281%29%2F%2F", http.StatusBadRequest, "", render.ErrInvalidCallback},
+	}
+	for _, tt := range tests {
+		ctx, w := gorookietest.NewContext(nil, httptest.NewRequest("GET", "/"+tt.query, nil))
+		err := ctx.JSONP(http.StatusOK, []int{1})
+		ctx.W.WriteHeaderNow()
+		if !errors.Is(err, tt.err) || w.Code != tt.status || ctx.StatusCode != tt.status || w.Body.String() != tt.body {
+			t.Errorf("%q: unexpected response %d %d %q %v", tt.query, w.Code, ctx.StatusCode, w.Body.String(), err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package go_rookie
+
+import (
+	"github.com/Jack-ZL/go_rookie/securecookie"
+	"time"
+)
+
+/**
+ * SetSignedCookie
+ * @Author：Jack-Z
+ * @Description: 设置HMAC签名的cookie，值对客户端可见但不能被篡改，maxAge大于0时过期时间同时写入签名的值中。
+ * 密钥见Engine.CookieKeys
+ * @receiver c
+ * @param name
+ * @param value
+ * @param maxAge 同SetCookie
+ * @param path
+ * @param domain
+ * @param secure
+ * @param httpOnly
+ * @return error 没有配置密钥或值过长
+ */
+func (c *Context) SetSignedCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) error {
+	encoded, err := c.cookieCodec().Sign(name, []byte(value), cookieExpires(maxAge))
+	if err != nil {
+		return err
+	}
+	c.setCookie(name, encoded, maxAge, path, domain, secure, httpOnly)
+	return nil
+}
+
+/**
+ * SignedCookie
+ * @Author：Jack-Z
+ * @Description: 读取并验证签名的cookie
+ * @receiver c
+ * @param name
+ * @return string
+ * @return error cookie不存在时返回http.ErrNoCookie，被篡改时返回securecookie.ErrInvalid，过期时返回securecookie.ErrExpired
+ */
+func (c *Context) SignedCookie(name string) (string, error) {
+	cookie, err := c.R.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	value, err := c.cookieCodec().Verify(name, cookie.Value)
+	return string(value), err
+}
+
+/**
+ * SetEncryptedCookie
+ * @Author：Jack-Z
+ * @Description: 设置AES-GCM加密的cookie，客户端既不能读取也不能篡改，过期时间同时加密在值中
+ * @receiver c
+ * @param name
+ * @param value
+ * @param maxAge 同SetCookie
+ * @param path
+ * @param domain
+ * @param secure
+ * @param httpOnly
+ * @return error 没有配置密钥或值过长
+ */
+func (c *Context) SetEncryptedCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) error {
+	encoded, err := c.cookieCodec().Encrypt(name, []byte(value), cookieExpires(maxAge))
+	if err != nil {
+		return err
+	}
+	c.setCookie(name, encoded, maxAge, path, domain, secure, httpOnly)
+	return nil
+}
+
+/**
+ * EncryptedCookie
+ * @Author：Jack-Z
+ * @Description: 读取并解密cookie
+ * @receiver c
+ * @param name
+ * @return string
+ * @return error 同SignedCookie
+ */
+func (c *Context) EncryptedCookie(name string) (string, error) {
+	cookie, err := c.R.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	value, err := c.cookieCodec().Decrypt(name, cookie.Value)
+	return string(value), err
+}
+
+// cookieCodec 首次使用时由Engine.CookieKeys创建，之后复用
+func (c *Context) cookieCodec() *securecookie.Codec {
+	e := c.engine
+	e.cookieOnce.Do(func() {
+		e.cookieCodec = securecookie.New(e.CookieKeys...)
+	})
+	return e.cookieCodec
+}
+
+// cookieExpires maxAge对应的过期时间，maxAge不大于0时为零值，即不在值中限制过期时间
+func cookieExpires(maxAge int) time.Time {
+	if maxAge <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(time.Duration(maxAge) * time.Second)
+}
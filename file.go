@@ -0,0 +1,155 @@
+package go_rookie
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+/**
+ * serveFile
+ * @Author：Jack-Z
+ * @Description: 输出文件内容，没有设置ETag时生成ETag：有修改时间时使用“修改时间（纳秒）-大小”生成弱ETag，
+ * 修改时间为零值（如embed.FS）时由文件内容计算强ETag。
+ * 条件请求（If-None-Match/If-Modified-Since/If-Range）和Range请求（含multipart/byteranges）由http.ServeContent处理，
+ * If-Range只接受强ETag，弱ETag时返回完整内容
+ * @receiver c
+ * @param f
+ * @param stat
+ */
+func (c *Context) serveFile(f io.ReadSeeker, stat os.FileInfo) {
+	if c.W.Header().Get("ETag") == "" {
+		etag := fmt.Sprintf(`W/"%x-%x"`, stat.ModTime().UnixNano(), stat.Size())
+		if stat.ModTime().IsZero() {
+			var err error
+			if etag, err = contentETag(f); err != nil {
+				c.String(http.StatusInternalServerError, "500 internal server error")
+				return
+			}
+		}
+		c.W.Header().Set("ETag", etag)
+	}
+	http.ServeContent(c.W, c.R, stat.Name(), stat.ModTime(), f)
+}
+
+// contentETag 根据文件内容计算强ETag，计算后把读取位置移回开头
+func contentETag(f io.ReadSeeker) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`, nil
+}
+
+// fileError 按打开文件的错误响应404、403或500
+func (c *Context) fileError(err error) {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		c.engine.noRoute(c)
+	case errors.Is(err, os.ErrPermission):
+		c.String(http.StatusForbidden, "403 forbidden")
+	default:
+		c.String(http.StatusInternalServerError, "500 internal server error")
+	}
+}
+
+/**
+ * contentDisposition
+ * @Author：Jack-Z
+ * @Description: 按RFC 6266生成Content-Disposition，文件名含非ASCII字符时同时提供
+ * ASCII的filename和UTF-8编码的filename*，不支持filename*的客户端使用前者
+ * @param dispositionType attachment或inline
+ * @param filename
+ * @return string
+ */
+func contentDisposition(dispositionType, filename string) string {
+	if filename == "" {
+		return dispositionType
+	}
+	fallback := asciiFilename(filename)
+	value := dispositionType + `; filename="` + fallback + `"`
+	if fallback != filename {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return value
+}
+
+// asciiFilename 把非ASCII字符、控制字符、引号和路径分隔符替换为“_”
+func asciiFilename(filename string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' || r == '/' {
+			return '_'
+		}
+		return r
+	}, filename)
+}
+
+// encodeRFC5987 按RFC 5987对attr-char以外的字节做百分号编码
+func encodeRFC5987(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || strings.IndexByte("!#$&+-.^_`|~", b) >= 0 {
+			sb.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", b)
+	}
+	return sb.String()
+}
+
+var errSeekBackward = errors.New("reader can not seek backward")
+
+/**
+ * forwardSeeker
+ * @Description: 把长度已知的io.Reader包装为只能向前seek的io.ReadSeeker，供http.ServeContent处理Range请求，
+ * 向前seek时丢弃中间的数据；多个Range需要按升序排列
+ */
+type forwardSeeker struct {
+	r        io.Reader
+	size     int64
+	pos      int64 // seek后的位置
+	consumed int64 // 已经从r读取的字节数
+}
+
+func (s *forwardSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	case io.SeekEnd:
+		offset += s.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	s.pos = offset
+	return offset, nil
+}
+
+func (s *forwardSeeker) Read(p []byte) (int, error) {
+	if s.pos < s.consumed {
+		return 0, errSeekBackward
+	}
+	if s.pos > s.consumed {
+		n, err := io.CopyN(io.Discard, s.r, s.pos-s.consumed)
+		s.consumed += n
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := s.r.Read(p)
+	s.consumed += int64(n)
+	s.pos = s.consumed
+	return n, err
+}
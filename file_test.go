@@ -0,0 +1,139 @@
+package go_rookie
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestContentDisposition(t *testing.T) {
+	cases := map[string]string{
+		"report.pdf":  `attachment; filename="report.pdf"`,
+		"报告 2024.pdf": `attachment; filename="__ 2024.pdf"; filename*=UTF-8''%E6%8A%A5%E5%91%8A%202024.pdf`,
+		`a"b.txt`:     `attachment; filename="a_b.txt"; filename*=UTF-8''a%22b.txt`,
+	}
+	for filename, want := range cases {
+		if got := contentDisposition("attachment", filename); got != want {
+			t.Errorf("%q: want %q, got %q", filename, want, got)
+		}
+	}
+}
+
+func TestFileRangeAndConditional(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data.txt")
+	os.WriteFile(name, []byte("0123456789"), 0644)
+	engine := New()
+	serve := func(header map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		ctx := engine.NewContext(w, r)
+		ctx.FileAttachment(name, "数据.txt")
+		ctx.W.WriteHeaderNow()
+		return w
+	}
+
+	w := serve(nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || w.Body.String() != "0123456789" || etag == "" || !strings.Contains(w.Header().Get("Content-Disposition"), "filename*=UTF-8''") {
+		t.Fatalf("unexpected response %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+	if !strings.HasPrefix(etag, "W/") {
+		t.Fatalf("expected weak ETag for file with modtime, got %q", etag)
+	}
+	if w = serve(map[string]string{"If-None-Match": etag}); w.Code != 304 {
+		t.Fatalf("weak If-None-Match: unexpected status %d", w.Code)
+	}
+	// If-Range只接受强验证器，弱ETag时返回完整内容
+	if w = serve(map[string]string{"Range": "bytes=2-4", "If-Range": etag}); w.Code != 200 || w.Body.String() != "0123456789" {
+		t.Fatalf("weak If-Range: unexpected response %d %q", w.Code, w.Body.String())
+	}
+	lastModified := serve(nil).Header().Get("Last-Modified")
+	if w = serve(map[string]string{"Range": "bytes=2-4", "If-Range": lastModified}); w.Code != 206 || w.Body.String() != "234" {
+		t.Fatalf("range: unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if w = serve(map[string]string{"Range": "bytes=2-4", "If-Range": `"stale"`}); w.Code != 200 {
+		t.Fatalf("stale If-Range: unexpected status %d", w.Code)
+	}
+	w = serve(map[string]string{"Range": "bytes=0-1,8-"})
+	if w.Code != 206 || !strings.HasPrefix(w.Header().Get("Content-Type"), "multipart/byteranges") || !strings.Contains(w.Body.String(), "89") {
+		t.Fatalf("multi range: unexpected response %d %v", w.Code, w.Header())
+	}
+}
+
+func TestDataFromReader(t *testing.T) {
+	engine := New()
+	for _, rangeHeader := range []string{"", "bytes=3-", "bytes=1-2,5-6"} {
+		r := httptest.NewRequest("GET", "/", nil)
+		if rangeHeader != "" {
+			r.Header.Set("Range", rangeHeader)
+		}
+		w := httptest.NewRecorder()
+		ctx := engine.NewContext(w, r)
+		// MultiReader没有实现io.Seeker，验证只能顺序读取的reader
+		reader := io.MultiReader(strings.NewReader("abcdefgh"))
+		if err := ctx.DataFromReader(200, 8, "text/plain", reader, map[string]string{"ETag": `"v1"`}); err != nil {
+			t.Fatal(err)
+		}
+		ctx.W.WriteHeaderNow()
+		switch rangeHeader {
+		case "":
+			if w.Code != 200 || w.Body.String() != "abcdefgh" {
+				t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+			}
+		case "bytes=3-":
+			if w.Code != 206 || w.Body.String() != "defgh" || w.Header().Get("Content-Range") != "bytes 3-7/8" {
+				t.Fatalf("unexpected response %d %q %v", w.Code, w.Body.String(), w.Header())
+			}
+		default:
+			if w.Code != 206 || !strings.Contains(w.Body.String(), "bc") || !strings.Contains(w.Body.String(), "fg") {
+				t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+			}
+		}
+	}
+}
+
+func TestFileZeroModTime(t *testing.T) {
+	// fstest.MapFS与embed.FS一样没有修改时间，ETag由文件内容计算
+	fsys := fstest.MapFS{"app.js": &fstest.MapFile{Data: []byte("console.log(1)")}}
+	engine := New()
+	serve := func(header map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		ctx := engine.NewContext(w, r)
+		ctx.FileFromFS("app.js", http.FS(fsys))
+		ctx.W.WriteHeaderNow()
+		return w
+	}
+
+	w := serve(nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || w.Body.String() != "console.log(1)" || etag == "" || strings.HasPrefix(etag, "W/") {
+		t.Fatalf("unexpected response %d %q %q", w.Code, w.Body.String(), etag)
+	}
+	if w = serve(map[string]string{"If-None-Match": etag}); w.Code != 304 {
+		t.Fatalf("If-None-Match: unexpected status %d", w.Code)
+	}
+	if w = serve(map[string]string{"Range": "bytes=0-6", "If-Range": etag}); w.Code != 206 || w.Body.String() != "console" {
+		t.Fatalf("If-Range: unexpected response %d %q", w.Code, w.Body.String())
+	}
+
+	// 重新构建后内容变化而大小不变，ETag随之变化
+	fsys["app.js"].Data = []byte("console.log(2)")
+	if w = serve(map[string]string{"If-None-Match": etag}); w.Code != 200 || w.Body.String() != "console.log(2)" {
+		t.Fatalf("changed content: unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if w = serve(map[string]string{"Range": "bytes=0-6", "If-Range": etag}); w.Code != 200 {
+		t.Fatalf("changed content If-Range: unexpected status %d", w.Code)
+	}
+}
@@ -27,27 +27,34 @@ func (t *TreeNode) Put(path string, gwName string) {
 			}
 		}
 		if !isMatch {
-			isEnd := false
-			if index == len(strs)-1 {
-				isEnd = true
-			}
 			node := &TreeNode{
 				Name:     name,
 				Children: make([]*TreeNode, 0),
-				IsEnd:    isEnd,
-				GwName:   gwName,
 			}
 			children = append(children, node)
 			t.Children = children
 			t = node
 		}
 	}
+	// 尾节点记录完整路由和网关名称
+	t.IsEnd = true
+	t.RouterName = path
+	t.GwName = gwName
 	t = root
 }
 
-func (t *TreeNode) Get(path string) *TreeNode {
+/**
+ * Get
+ * @Author：Jack-Z
+ * @Description: 路由匹配，同时捕获路径参数（:name、* 匹配单段，** 匹配剩余路径）
+ * @receiver t
+ * @param path
+ * @return *TreeNode
+ * @return map[string]string
+ */
+func (t *TreeNode) Get(path string) (*TreeNode, map[string]string) {
 	strs := strings.Split(path, "/")
-	routerName := ""
+	params := make(map[string]string)
 	for index, name := range strs {
 		if index == 0 {
 			continue
@@ -58,11 +65,12 @@ func (t *TreeNode) Get(path string) *TreeNode {
 		for _, node := range children {
 			if node.Name == name || node.Name == "*" || strings.Contains(node.Name, ":") {
 				isMatch = true
-				routerName += "/" + node.Name
-				node.RouterName = routerName
+				if key, ok := paramKey(node.Name); ok {
+					params[key] = name
+				}
 				t = node
 				if index == len(strs)-1 {
-					return node
+					return node, params
 				}
 				break
 			}
@@ -71,12 +79,22 @@ func (t *TreeNode) Get(path string) *TreeNode {
 		if !isMatch {
 			for _, node := range children {
 				if node.Name == "**" {
-					routerName += "/" + node.Name
-					node.RouterName = routerName
-					return node
+					params["**"] = strings.Join(strs[index:], "/")
+					return node, params
 				}
 			}
 		}
 	}
-	return nil
+	return nil, nil
+}
+
+// paramKey 获取路由段对应的参数名，“:id”对应“id”，“*”对应“*”
+func paramKey(name string) (string, bool) {
+	if name == "*" {
+		return name, true
+	}
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[i+1:], true
+	}
+	return "", false
 }
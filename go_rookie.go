@@ -7,12 +7,16 @@ import (
 	grLog "github.com/Jack-ZL/go_rookie/log"
 	"github.com/Jack-ZL/go_rookie/register"
 	"github.com/Jack-ZL/go_rookie/render"
+	"github.com/Jack-ZL/go_rookie/securecookie"
+	"github.com/Jack-ZL/go_rookie/websocket"
 	"html/template"
 	"log"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
 	"sync"
+	"time"
 )
 
 const ANY = "ANY"
@@ -27,33 +31,79 @@ type MiddlewareFunc func(handlerFunc HandlerFunc) HandlerFunc
  *  @Description: 路由分组
  */
 type routerGroup struct {
-	name               string
-	handlerFuncMap     map[string]map[string]HandlerFunc
-	middlewaresFuncMap map[string]map[string][]MiddlewareFunc
-	handlerMethodMap   map[string][]string
-	treeNode           *treeNode
-	middlewares        []MiddlewareFunc // 请求处理前的中间件
+	name             string
+	handlerFuncMap   map[string]map[string]HandlerFunc
+	handlerMethodMap map[string][]string
+	middlewares      []HandlerFunc // 请求处理前的中间件
+	router           *router
+	parent           *routerGroup // 父分组，顶层分组为nil
 }
 
 func (r *routerGroup) Use(middlewareFunc ...MiddlewareFunc) {
-	r.middlewares = append(r.middlewares, middlewareFunc...)
+	r.middlewares = append(r.middlewares, middlewareHandlers(middlewareFunc)...)
+	r.router.engine.rebuildHandlers()
 }
 
-func (r *routerGroup) methodHandler(name string, method string, h HandlerFunc, ctx *Context) {
-	// 组通用的中间件
-	if r.middlewares != nil {
-		for _, middlewareFunc := range r.middlewares {
-			h = middlewareFunc(h)
+/**
+ * UseHandler
+ * @Author：Jack-Z
+ * @Description: 添加处理链形式的中间件，中间件内调用ctx.Next()执行后续处理，
+ * 调用ctx.Abort()中断，两者都不调用时执行完自动进入下一个处理函数
+ * @receiver r
+ * @param handlers
+ */
+func (r *routerGroup) UseHandler(handlers ...HandlerFunc) {
+	r.middlewares = append(r.middlewares, handlers...)
+	r.router.engine.rebuildHandlers()
+}
+
+/**
+ * middlewareHandler
+ * @Author：Jack-Z
+ * @Description: 将MiddlewareFunc转换为处理链中的一环，只在注册时包装一次。
+ * 中间件没有调用next，或后续处理因panic被中途恢复时，中断剩余的处理函数
+ * @param middlewareFunc
+ * @return HandlerFunc
+ */
+func middlewareHandler(middlewareFunc MiddlewareFunc) HandlerFunc {
+	h := middlewareFunc(func(ctx *Context) {
+		ctx.Next()
+	})
+	return func(ctx *Context) {
+		h(ctx)
+		if ctx.index < len(ctx.handlers) {
+			ctx.Abort()
 		}
 	}
-	// 路由级别中间件
-	middlewareFuncs := r.middlewaresFuncMap[name][method]
-	if middlewareFuncs != nil {
-		for _, middlewareFunc := range middlewareFuncs {
-			h = middlewareFunc(h)
-		}
+}
+
+func middlewareHandlers(middlewareFuncs []MiddlewareFunc) []HandlerFunc {
+	handlers := make([]HandlerFunc, len(middlewareFuncs))
+	for i, middlewareFunc := range middlewareFuncs {
+		handlers[i] = middlewareHandler(middlewareFunc)
+	}
+	return handlers
+}
+
+/**
+ * combineHandlers
+ * @Author：Jack-Z
+ * @Description: 按“全局 -> 父分组 -> 当前分组 -> 路由”的顺序组装处理链
+ * @receiver r
+ * @param handlers 路由级别中间件和处理函数
+ * @return []HandlerFunc
+ */
+func (r *routerGroup) combineHandlers(handlers ...HandlerFunc) []HandlerFunc {
+	groups := make([]*routerGroup, 0)
+	for group := r; group != nil; group = group.parent {
+		groups = append(groups, group)
+	}
+	chain := make([]HandlerFunc, 0)
+	chain = append(chain, r.router.engine.middles...)
+	for i := len(groups) - 1; i >= 0; i-- {
+		chain = append(chain, groups[i].middlewares...)
 	}
-	h(ctx)
+	return append(chain, handlers...)
 }
 
 /**
@@ -65,19 +115,35 @@ func (r *routerGroup) methodHandler(name string, method string, h HandlerFunc, c
  * @param method
  * @param handlerFunc
  */
-func (r *routerGroup) handle(name string, method string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	_, ok := r.handlerFuncMap[name]
-	if !ok {
-		r.handlerFuncMap[name] = make(map[string]HandlerFunc)
-		r.middlewaresFuncMap[name] = make(map[string][]MiddlewareFunc)
+func (r *routerGroup) handle(name string, method string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	fullPath := joinPaths(r.name, name)
+	node, err := r.router.treeNode.Put(fullPath)
+	if err != nil {
+		panic(err)
 	}
-	_, ok = r.handlerFuncMap[name][method]
-	if ok {
-		panic("有重复的路由")
+	// “/user/:id”与“/user/{id}”对应同一节点，写法不同时视为冲突
+	if node.pattern != "" && node.pattern != fullPath {
+		panic(fmt.Errorf("route conflict: '%s' conflicts with existing '%s'", fullPath, node.pattern))
+	}
+	if _, ok := node.routes[method]; ok {
+		panic(fmt.Errorf("duplicate route: %s %s", method, fullPath))
+	}
+	route := &Route{
+		method:      method,
+		path:        fullPath,
+		handler:     handlerFunc,
+		middlewares: middlewareHandlers(middlewareFunc),
+		group:       r,
+	}
+	route.compile()
+	node.addRoute(fullPath, method, route)
+	if _, ok := r.handlerFuncMap[name]; !ok {
+		r.handlerFuncMap[name] = make(map[string]HandlerFunc)
 	}
 	r.handlerFuncMap[name][method] = handlerFunc
-	r.middlewaresFuncMap[name][method] = append(r.middlewaresFuncMap[name][method], middlewareFunc...)
-	r.treeNode.Put(name)
+	r.handlerMethodMap[name] = append(r.handlerMethodMap[name], method)
+	r.router.routes = append(r.router.routes, route)
+	return route
 }
 
 /**
@@ -88,37 +154,40 @@ func (r *routerGroup) handle(name string, method string, handlerFunc HandlerFunc
  * @param name
  * @param handlerFunc
  */
-func (r *routerGroup) Any(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, ANY, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Any(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, ANY, handlerFunc, middlewareFunc...)
 }
 
-func (r *routerGroup) Get(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodGet, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Get(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodGet, handlerFunc, middlewareFunc...)
 }
 
-func (r *routerGroup) Post(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodPost, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Post(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodPost, handlerFunc, middlewareFunc...)
 }
 
-func (r *routerGroup) Delete(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodDelete, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Delete(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodDelete, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Put(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodPut, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Put(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodPut, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Patch(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodPatch, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Patch(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodPatch, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Options(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodOptions, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Options(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodOptions, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Head(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodHead, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Head(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodHead, handlerFunc, middlewareFunc...)
 }
 
 type router struct {
 	routerGroup []*routerGroup
 	engine      *Engine
+	treeNode    *treeNode // 所有分组共用的路由树
+	routes      []*Route  // 按注册顺序记录的路由
+	host        string    // 绑定的域名规则，默认路由器为空
 }
 
 /**
@@ -130,17 +199,30 @@ type router struct {
  * @return *routerGroup
  */
 func (r *router) Group(name string) *routerGroup {
+	return r.newGroup(joinPaths("", name), nil)
+}
+
+/**
+ * Group
+ * @Author：Jack-Z
+ * @Description: 创建子分组，如“/api” -> “/v1” -> “/users”，
+ * 子分组的路由前缀为父分组前缀拼接name，并依次继承父分组的中间件
+ * @receiver r
+ * @param name
+ * @return *routerGroup
+ */
+func (r *routerGroup) Group(name string) *routerGroup {
+	return r.router.newGroup(joinPaths(r.name, name), r)
+}
+
+func (r *router) newGroup(name string, parent *routerGroup) *routerGroup {
 	rg := &routerGroup{
-		name:               name,
-		handlerFuncMap:     make(map[string]map[string]HandlerFunc),
-		middlewaresFuncMap: make(map[string]map[string][]MiddlewareFunc),
-		handlerMethodMap:   make(map[string][]string),
-		treeNode: &treeNode{
-			name:     "/",
-			children: make([]*treeNode, 0),
-		},
+		name:             name,
+		handlerFuncMap:   make(map[string]map[string]HandlerFunc),
+		handlerMethodMap: make(map[string][]string),
+		router:           r,
+		parent:           parent,
 	}
-	rg.Use(r.engine.middles...)
 	r.routerGroup = append(r.routerGroup, rg)
 	return rg
 }
@@ -153,8 +235,15 @@ type Engine struct {
 	HTMLRender       render.HTMLRender
 	pool             sync.Pool
 	Logger           *grLog.Logger
-	middles          []MiddlewareFunc
+	middles          []HandlerFunc
 	errorHandler     ErrorHandler
+	noRoute          HandlerFunc // 路由不存在时的处理函数
+	noMethod         HandlerFunc // 请求方式不被允许时的处理函数
+	allNoRoute       []HandlerFunc
+	allNoMethod      []HandlerFunc
+	allOptions       []HandlerFunc
+	namedRoutes      map[string]*Route
+	hosts            []*hostRouter // 绑定域名的路由器
 	OpenGateway      bool
 	gatewayConfigs   []gateway.GWConfig
 	gatewayTreeNode  *gateway.TreeNode
@@ -162,6 +251,24 @@ type Engine struct {
 	RegisterType     string              //注册类型
 	RegisterOption   register.Option     //注册的配置项
 	RegisterCli      register.GrRegister //注册的客户端
+	ServerOption     ServerOption        //http.Server的配置项
+	ShutdownTimeout  time.Duration       //优雅关闭时等待处理中请求的最长时间，为0时使用默认值
+	HotRestart       bool                //收到SIGHUP时热重启，见Restart
+	Upgrader         websocket.Upgrader  //websocket路由和Context.Upgrade默认使用的配置
+	SecureJSONPrefix string              //Context.SecureJSON的前缀，为空时使用render.DefaultSecureJSONPrefix
+	CookieKeys       [][]byte            //签名和加密cookie的密钥，第一个用于生成，其余用于验证轮换前的cookie，首次使用后修改不生效
+	cookieOnce       sync.Once
+	cookieCodec      *securecookie.Codec //由CookieKeys创建，所有请求共用
+	shutdownHooks    []func()            //关闭时执行的钩子
+	servers          []*http.Server      //运行中的服务
+	shuttingDown     bool                //是否已开始关闭
+	serverMu         sync.Mutex
+	registerOnce     sync.Once
+	registerErr      error
+	registered       bool //是否由startRegister注册了服务，关闭时只注销自己注册的服务
+	shutdownOnce     sync.Once
+	shutdownErr      error
+	done             chan struct{} //关闭流程结束后关闭
 }
 
 /**
@@ -172,13 +279,21 @@ type Engine struct {
  */
 func New() *Engine {
 	engine := &Engine{
-		router: router{},
+		router: router{
+			treeNode: &treeNode{},
+		},
 		gatewayTreeNode: &gateway.TreeNode{
 			Name:     "/",
 			Children: make([]*gateway.TreeNode, 0),
 		},
 		gatewayConfigMap: make(map[string]gateway.GWConfig),
+		noRoute:          defaultNoRoute,
+		noMethod:         defaultNoMethod,
+		namedRoutes:      make(map[string]*Route),
+		done:             make(chan struct{}),
 	}
+	engine.router.engine = engine
+	engine.rebuildHandlers()
 	engine.pool.New = func() any {
 		return engine.allocateContext()
 	}
@@ -193,7 +308,6 @@ func Default() *Engine {
 		engine.Logger.SetLogPath(logPath.(string))
 	}
 	engine.Use(Logging, Recovery)
-	engine.router.engine = engine
 	return engine
 }
 
@@ -214,6 +328,21 @@ func (e *Engine) SetFuncMap(funcMap template.FuncMap) {
 	e.funcMap = funcMap
 }
 
+/**
+ * templateFuncMap
+ * @Author：Jack-Z
+ * @Description: 模板函数，默认提供“url”函数用于按路由名称生成链接，如 {{url "user.show" "id" 1}}
+ * @receiver e
+ * @return template.FuncMap
+ */
+func (e *Engine) templateFuncMap() template.FuncMap {
+	funcMap := template.FuncMap{"url": e.URL}
+	for k, v := range e.funcMap {
+		funcMap[k] = v
+	}
+	return funcMap
+}
+
 /**
  * LoadTemplate
  * @Author：Jack-Z
@@ -222,7 +351,7 @@ func (e *Engine) SetFuncMap(funcMap template.FuncMap) {
  * @param pattern
  */
 func (e *Engine) LoadTemplate(pattern string) {
-	t := template.Must(template.New("").Funcs(e.funcMap).ParseGlob(pattern))
+	t := template.Must(template.New("").Funcs(e.templateFuncMap()).ParseGlob(pattern))
 	e.SetHtmlTemplate(t)
 }
 
@@ -235,7 +364,7 @@ func (e *Engine) LoadTemplate(pattern string) {
 func (e *Engine) LoadTemplateConf() {
 	pattern, ok := config.Conf.Template["pattern"]
 	if ok {
-		t := template.Must(template.New("").Funcs(e.funcMap).ParseGlob(pattern.(string)))
+		t := template.Must(template.New("").Funcs(e.templateFuncMap()).ParseGlob(pattern.(string)))
 		e.SetHtmlTemplate(t)
 	}
 }
@@ -261,13 +390,31 @@ func (e *Engine) SetHtmlTemplate(t *template.Template) {
  */
 func (e *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := e.pool.Get().(*Context)
-	ctx.W = w
-	ctx.R = r
+	ctx.reset(w, r)
 	ctx.Logger = e.Logger
 	e.httpRequestHandler(ctx, w, r)
+	// 只设置了状态码而没有写响应体时，在这里写出响应头
+	ctx.W.WriteHeaderNow()
 	e.pool.Put(ctx)
 }
 
+/**
+ * NewContext
+ * @Author：Jack-Z
+ * @Description: 创建不经过路由的独立Context，用于单元测试处理函数、中间件，
+ * 或在处理链之外使用；返回的Context不会放回pool
+ * @receiver e
+ * @param w
+ * @param r
+ * @return *Context
+ */
+func (e *Engine) NewContext(w http.ResponseWriter, r *http.Request) *Context {
+	ctx := e.allocateContext().(*Context)
+	ctx.reset(w, r)
+	ctx.Logger = e.Logger
+	return ctx
+}
+
 /**
  * httpRequestHandler
  * @Author：Jack-Z
@@ -277,14 +424,21 @@ func (e *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
  * @param r
  */
 func (e *Engine) httpRequestHandler(ctx *Context, w http.ResponseWriter, r *http.Request) {
+	if len(e.hosts) > 0 {
+		if router, params := e.matchHost(r.Host); router != nil {
+			ctx.HostParams = params
+			e.routeHandler(ctx, router)
+			return
+		}
+	}
 	if e.OpenGateway {
 		path := r.URL.Path
-		node := e.gatewayTreeNode.Get(path)
-		if node == nil {
-			ctx.W.WriteHeader(http.StatusNotFound)
-			fmt.Fprintln(ctx.W, ctx.R.RequestURI+" not found")
+		node, params := e.gatewayTreeNode.Get(path)
+		if node == nil || !node.IsEnd {
+			ctx.handle(e.allNoRoute)
 			return
 		}
+		ctx.Params = params
 		gwConfig := e.gatewayConfigMap[node.GwName]
 		gwConfig.Header(ctx.R)
 		addr, err := e.RegisterCli.GetValue(gwConfig.ServiceName)
@@ -324,85 +478,150 @@ func (e *Engine) httpRequestHandler(ctx *Context, w http.ResponseWriter, r *http
 			ModifyResponse: response,
 			ErrorHandler:   handler,
 		}
-		proxy.ServeHTTP(w, r)
+		proxy.ServeHTTP(ctx.W, r)
 		return
 	}
 	
-	method := r.Method
-	for _, group := range e.routerGroup {
-		routerName := SubStringLast(r.URL.Path, "/"+group.name)
-		node := group.treeNode.Get(routerName)
-		if node != nil && node.isEnd {
-			// 路由匹配
-			handle, ok := group.handlerFuncMap[node.routerName][ANY]
-			if ok {
-				group.methodHandler(node.routerName, ANY, handle, ctx)
-				return
-			}
-			
-			handle, ok = group.handlerFuncMap[node.routerName][method]
-			if ok {
-				group.methodHandler(node.routerName, method, handle, ctx)
-				return
-			}
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			fmt.Fprintf(w, "%s %s not allowed \n", r.RequestURI, method)
+	e.routeHandler(ctx, &e.router)
+}
+
+/**
+ * routeHandler
+ * @Author：Jack-Z
+ * @Description: 在路由器中匹配路由并执行处理链
+ * @receiver e
+ * @param ctx
+ * @param router
+ */
+func (e *Engine) routeHandler(ctx *Context, router *router) {
+	method := ctx.R.Method
+	node, params := router.treeNode.Get(ctx.R.URL.Path)
+	if node != nil {
+		// 路由匹配
+		ctx.Params = params
+		route, ok := node.routes[ANY]
+		if !ok {
+			route, ok = node.routes[method]
+		}
+		// HEAD请求由GET路由处理，响应体会被net/http丢弃
+		if !ok && method == http.MethodHead {
+			route, ok = node.routes[http.MethodGet]
+		}
+		if ok {
+			ctx.handle(route.handlers)
+			return
+		}
+		ctx.W.Header().Set("Allow", node.allowMethods())
+		if method == http.MethodOptions {
+			ctx.handle(e.allOptions)
 			return
 		}
+		ctx.handle(e.allNoMethod)
+		return
 	}
-	w.WriteHeader(http.StatusNotFound)
-	fmt.Fprintf(w, "%s not found \n", r.RequestURI)
+	ctx.handle(e.allNoRoute)
 }
 
 /**
- * Run
+ * NoRoute
  * @Author：Jack-Z
- * @Description: 启动并建监听一个端口
+ * @Description: 设置路由不存在（404）时的处理函数，会经过全局中间件
  * @receiver e
+ * @param handler
  */
-func (e *Engine) Run(addr string) {
-	if e.RegisterType == "nacos" {
-		r := register.GrNacosRegister{}
-		err := r.CreateCli(e.RegisterOption)
-		if err != nil {
-			panic(err)
-		}
-		e.RegisterCli = &r
-	}
-	if e.RegisterType == "etcd" {
-		r := register.GrEtcdRegister{}
-		err := r.CreateCli(e.RegisterOption)
-		if err != nil {
-			panic(err)
+func (e *Engine) NoRoute(handler HandlerFunc) {
+	e.noRoute = handler
+	e.rebuildHandlers()
+}
+
+/**
+ * NoMethod
+ * @Author：Jack-Z
+ * @Description: 设置请求方式不被允许（405）时的处理函数，会经过全局中间件，
+ * 执行前已写入Allow响应头
+ * @receiver e
+ * @param handler
+ */
+func (e *Engine) NoMethod(handler HandlerFunc) {
+	e.noMethod = handler
+	e.rebuildHandlers()
+}
+
+/**
+ * rebuildHandlers
+ * @Author：Jack-Z
+ * @Description: 重新组装所有处理链。处理链在注册时组装好，请求时直接执行；
+ * 中间件发生变化时调用，保证之后添加的全局或父分组中间件同样生效
+ * @receiver e
+ */
+func (e *Engine) rebuildHandlers() {
+	e.allNoRoute = e.combineHandlers(e.noRoute)
+	e.allNoMethod = e.combineHandlers(e.noMethod)
+	e.allOptions = e.combineHandlers(optionsHandler)
+	for _, router := range e.allRouters() {
+		for _, route := range router.routes {
+			route.compile()
 		}
-		e.RegisterCli = &r
-	}
-	
-	http.Handle("/", e)
-	err := http.ListenAndServe(addr, nil)
-	if err != nil {
-		log.Fatal(err)
 	}
 }
 
+// combineHandlers 在全局中间件之后追加处理函数
+func (e *Engine) combineHandlers(handlers ...HandlerFunc) []HandlerFunc {
+	chain := make([]HandlerFunc, 0, len(e.middles)+len(handlers))
+	chain = append(chain, e.middles...)
+	return append(chain, handlers...)
+}
+
+func defaultNoRoute(ctx *Context) {
+	ctx.String(http.StatusNotFound, "%s not found \n", ctx.R.RequestURI)
+}
+
+func defaultNoMethod(ctx *Context) {
+	ctx.String(http.StatusMethodNotAllowed, "%s %s not allowed \n", ctx.R.RequestURI, ctx.R.Method)
+}
+
+// optionsHandler 自动应答未注册OPTIONS的路由，Allow响应头已写入
+func optionsHandler(ctx *Context) {
+	ctx.W.WriteHeader(http.StatusNoContent)
+	ctx.StatusCode = http.StatusNoContent
+}
+
 /**
- * RunTLS
+ * allowMethods
  * @Author：Jack-Z
- * @Description: 支持https安全
- * @receiver e
- * @param addr
- * @param certFile
- * @param keyFile
+ * @Description: 根据节点上注册的请求方式生成Allow响应头，GET路由自动支持HEAD，所有路由自动支持OPTIONS
+ * @receiver t 尾节点
+ * @return string
  */
-func (e *Engine) RunTLS(addr, certFile, keyFile string) {
-	err := http.ListenAndServeTLS(addr, certFile, keyFile, e.Handler())
-	if err != nil {
-		log.Fatal(err)
+func (t *treeNode) allowMethods() string {
+	methods := make([]string, 0, len(t.methods)+2)
+	methods = append(methods, t.methods...)
+	_, hasGet := t.routes[http.MethodGet]
+	_, hasHead := t.routes[http.MethodHead]
+	if hasGet && !hasHead {
+		methods = append(methods, http.MethodHead)
 	}
+	if _, ok := t.routes[http.MethodOptions]; !ok {
+		methods = append(methods, http.MethodOptions)
+	}
+	return strings.Join(methods, ", ")
 }
 
 func (e *Engine) Use(middles ...MiddlewareFunc) {
-	e.middles = append(e.middles, middles...)
+	e.middles = append(e.middles, middlewareHandlers(middles)...)
+	e.rebuildHandlers()
+}
+
+/**
+ * UseHandler
+ * @Author：Jack-Z
+ * @Description: 添加处理链形式的全局中间件，见routerGroup.UseHandler
+ * @receiver e
+ * @param handlers
+ */
+func (e *Engine) UseHandler(handlers ...HandlerFunc) {
+	e.middles = append(e.middles, handlers...)
+	e.rebuildHandlers()
 }
 
 /**
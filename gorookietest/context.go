@@ -0,0 +1,46 @@
+package gorookietest
+
+import (
+	"github.com/Jack-ZL/go_rookie"
+	"net/http"
+	"net/http/httptest"
+)
+
+/**
+ * NewContext
+ * @Author：Jack-Z
+ * @Description: 创建独立的Context，用于直接调用单个HandlerFunc或MiddlewareFunc，如：
+ * ctx, w := gorookietest.NewContext(nil, httptest.NewRequest("GET", "/", nil))
+ * handler(ctx)
+ * 处理函数只设置了状态码而没有写响应体时，需调用ctx.W.WriteHeaderNow()后再检查w.Code
+ * @param engine 为nil时使用go_rookie.New()
+ * @param r 为nil时使用GET /
+ * @return *go_rookie.Context
+ * @return *httptest.ResponseRecorder 记录响应
+ */
+func NewContext(engine *go_rookie.Engine, r *http.Request) (*go_rookie.Context, *httptest.ResponseRecorder) {
+	if engine == nil {
+		engine = go_rookie.New()
+	}
+	if r == nil {
+		r = httptest.NewRequest(http.MethodGet, "/", nil)
+	}
+	w := httptest.NewRecorder()
+	return engine.NewContext(w, r), w
+}
+
+/**
+ * RunMiddleware
+ * @Author：Jack-Z
+ * @Description: 在ctx上执行中间件，next为中间件之后的处理函数，为nil时使用空函数
+ * @param ctx
+ * @param middleware
+ * @param next
+ */
+func RunMiddleware(ctx *go_rookie.Context, middleware go_rookie.MiddlewareFunc, next go_rookie.HandlerFunc) {
+	if next == nil {
+		next = func(ctx *go_rookie.Context) {}
+	}
+	middleware(next)(ctx)
+	ctx.W.WriteHeaderNow()
+}
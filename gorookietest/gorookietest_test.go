@@ -0,0 +1,66 @@
+package gorookietest
+
+import (
+	"github.com/Jack-ZL/go_rookie"
+	"net/http"
+	"testing"
+)
+
+type user struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestRequest(t *testing.T) {
+	engine := go_rookie.New()
+	engine.Group("").Post("/user/:id", func(ctx *go_rookie.Context) {
+		var u user
+		if err := ctx.BindJson(&u); err != nil {
+			ctx.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		ctx.W.Header().Set("X-Id", ctx.Param("id"))
+		ctx.JSON(http.StatusOK, map[string]any{"user": u, "token": ctx.GetHeader("Token")})
+	})
+
+	Post(t, engine, "/user/12").
+		Header("Token", "abc").
+		JSON(user{Name: "jack", Age: 18}).
+		Do().
+		AssertStatus(http.StatusOK).
+		AssertHeader("X-Id", "12").
+		AssertJSON(`{"user":{"name":"jack","age":18},"token":"abc"}`)
+
+	Get(t, engine, "/missing").Do().AssertStatus(http.StatusNotFound)
+}
+
+func TestMultipart(t *testing.T) {
+	engine := go_rookie.New()
+	engine.Group("").Post("/upload", func(ctx *go_rookie.Context) {
+		file := ctx.FormFile("file")
+		name, _ := ctx.GetPostForm("name")
+		ctx.String(http.StatusOK, "%s:%s:%d", name, file.Filename, file.Size)
+	})
+	Post(t, engine, "/upload").
+		Field("name", "avatar").
+		File("file", "a.png", []byte("12345")).
+		Do().
+		AssertStatus(http.StatusOK).
+		AssertBody("avatar:a.png:5")
+}
+
+func TestContext(t *testing.T) {
+	ctx, w := NewContext(nil, nil)
+	RunMiddleware(ctx, func(next go_rookie.HandlerFunc) go_rookie.HandlerFunc {
+		return func(ctx *go_rookie.Context) {
+			ctx.Set("user", "jack")
+			next(ctx)
+		}
+	}, func(ctx *go_rookie.Context) {
+		name, _ := ctx.Get("user")
+		ctx.String(http.StatusCreated, "hello %s", name)
+	})
+	if w.Code != http.StatusCreated || w.Body.String() != "hello jack" {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+}
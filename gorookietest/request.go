@@ -0,0 +1,256 @@
+package gorookietest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+/**
+ * Request
+ * @Description: 测试请求构造器，Do时直接调用handler.ServeHTTP，不需要监听端口
+ */
+type Request struct {
+	t           testing.TB
+	handler     http.Handler
+	method      string
+	path        string
+	host        string
+	query       url.Values
+	header      http.Header
+	cookies     []*http.Cookie
+	body        io.Reader
+	contentType string
+	fields      map[string][]string   // multipart的普通字段
+	files       map[string][]fileData // multipart的文件字段
+}
+
+type fileData struct {
+	filename string
+	content  []byte
+}
+
+/**
+ * NewRequest
+ * @Author：Jack-Z
+ * @Description: 创建测试请求，如：
+ * gorookietest.NewRequest(t, engine, http.MethodPost, "/user").JSON(user).Do().AssertStatus(200)
+ * @param t 构造请求或断言失败时调用t.Fatal
+ * @param handler 通常为*go_rookie.Engine
+ * @param method
+ * @param path 可以带query参数
+ * @return *Request
+ */
+func NewRequest(t testing.TB, handler http.Handler, method, path string) *Request {
+	return &Request{
+		t:       t,
+		handler: handler,
+		method:  method,
+		path:    path,
+		query:   url.Values{},
+		header:  http.Header{},
+	}
+}
+
+func Get(t testing.TB, handler http.Handler, path string) *Request {
+	return NewRequest(t, handler, http.MethodGet, path)
+}
+
+func Post(t testing.TB, handler http.Handler, path string) *Request {
+	return NewRequest(t, handler, http.MethodPost, path)
+}
+
+func Put(t testing.TB, handler http.Handler, path string) *Request {
+	return NewRequest(t, handler, http.MethodPut, path)
+}
+
+func Delete(t testing.TB, handler http.Handler, path string) *Request {
+	return NewRequest(t, handler, http.MethodDelete, path)
+}
+
+// Header 设置请求头
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Host 设置请求的Host
+func (r *Request) Host(host string) *Request {
+	r.host = host
+	return r
+}
+
+// Query 添加query参数
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Cookie 添加cookie
+func (r *Request) Cookie(cookie *http.Cookie) *Request {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+/**
+ * Body
+ * @Author：Jack-Z
+ * @Description: 设置原始请求体
+ * @receiver r
+ * @param contentType
+ * @param body
+ * @return *Request
+ */
+func (r *Request) Body(contentType string, body io.Reader) *Request {
+	r.contentType = contentType
+	r.body = body
+	return r
+}
+
+/**
+ * JSON
+ * @Author：Jack-Z
+ * @Description: 以JSON编码obj作为请求体
+ * @receiver r
+ * @param obj
+ * @return *Request
+ */
+func (r *Request) JSON(obj any) *Request {
+	r.t.Helper()
+	data, err := json.Marshal(obj)
+	if err != nil {
+		r.t.Fatalf("gorookietest: marshal json body: %v", err)
+	}
+	return r.Body("application/json", bytes.NewReader(data))
+}
+
+/**
+ * Form
+ * @Author：Jack-Z
+ * @Description: 以application/x-www-form-urlencoded编码表单作为请求体
+ * @receiver r
+ * @param values
+ * @return *Request
+ */
+func (r *Request) Form(values url.Values) *Request {
+	return r.Body("application/x-www-form-urlencoded", strings.NewReader(values.Encode()))
+}
+
+/**
+ * Field
+ * @Author：Jack-Z
+ * @Description: 添加multipart表单字段，与File一起使用时请求体为multipart/form-data
+ * @receiver r
+ * @param key
+ * @param value
+ * @return *Request
+ */
+func (r *Request) Field(key, value string) *Request {
+	if r.fields == nil {
+		r.fields = make(map[string][]string)
+	}
+	r.fields[key] = append(r.fields[key], value)
+	return r
+}
+
+/**
+ * File
+ * @Author：Jack-Z
+ * @Description: 添加multipart文件字段
+ * @receiver r
+ * @param field 表单字段名
+ * @param filename 文件名
+ * @param content 文件内容
+ * @return *Request
+ */
+func (r *Request) File(field, filename string, content []byte) *Request {
+	if r.files == nil {
+		r.files = make(map[string][]fileData)
+	}
+	r.files[field] = append(r.files[field], fileData{filename: filename, content: content})
+	return r
+}
+
+/**
+ * Build
+ * @Author：Jack-Z
+ * @Description: 生成*http.Request
+ * @receiver r
+ * @return *http.Request
+ */
+func (r *Request) Build() *http.Request {
+	r.t.Helper()
+	body := r.body
+	contentType := r.contentType
+	if r.fields != nil || r.files != nil {
+		buf := &bytes.Buffer{}
+		mw := multipart.NewWriter(buf)
+		for key, values := range r.fields {
+			for _, value := range values {
+				if err := mw.WriteField(key, value); err != nil {
+					r.t.Fatalf("gorookietest: write multipart field: %v", err)
+				}
+			}
+		}
+		for field, files := range r.files {
+			for _, file := range files {
+				fw, err := mw.CreateFormFile(field, file.filename)
+				if err == nil {
+					_, err = fw.Write(file.content)
+				}
+				if err != nil {
+					r.t.Fatalf("gorookietest: write multipart file: %v", err)
+				}
+			}
+		}
+		if err := mw.Close(); err != nil {
+			r.t.Fatalf("gorookietest: close multipart writer: %v", err)
+		}
+		body = buf
+		contentType = mw.FormDataContentType()
+	}
+
+	req := httptest.NewRequest(r.method, r.path, body)
+	if len(r.query) > 0 {
+		query := req.URL.Query()
+		for key, values := range r.query {
+			query[key] = append(query[key], values...)
+		}
+		req.URL.RawQuery = query.Encode()
+		req.RequestURI = req.URL.RequestURI()
+	}
+	if r.host != "" {
+		req.Host = r.host
+	}
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for _, cookie := range r.cookies {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+/**
+ * Do
+ * @Author：Jack-Z
+ * @Description: 通过handler.ServeHTTP执行请求
+ * @receiver r
+ * @return *Response
+ */
+func (r *Request) Do() *Response {
+	r.t.Helper()
+	req := r.Build()
+	recorder := httptest.NewRecorder()
+	r.handler.ServeHTTP(recorder, req)
+	return &Response{t: r.t, ResponseRecorder: recorder}
+}
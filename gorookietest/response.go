@@ -0,0 +1,112 @@
+package gorookietest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/**
+ * Response
+ * @Description: 测试响应，断言方法失败时调用t.Fatal，返回自身以便链式调用
+ */
+type Response struct {
+	*httptest.ResponseRecorder
+	t testing.TB
+}
+
+// BodyString 响应体
+func (r *Response) BodyString() string {
+	return r.Body.String()
+}
+
+// Cookies 响应设置的cookie
+func (r *Response) Cookies() []*http.Cookie {
+	return r.Result().Cookies()
+}
+
+/**
+ * DecodeJSON
+ * @Author：Jack-Z
+ * @Description: 把响应体解码到obj，失败时t.Fatal
+ * @receiver r
+ * @param obj
+ * @return *Response
+ */
+func (r *Response) DecodeJSON(obj any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body.Bytes(), obj); err != nil {
+		r.t.Fatalf("gorookietest: decode json body %q: %v", r.Body.String(), err)
+	}
+	return r
+}
+
+// AssertStatus 断言状态码
+func (r *Response) AssertStatus(code int) *Response {
+	r.t.Helper()
+	if r.Code != code {
+		r.t.Fatalf("gorookietest: want status %d, got %d, body: %s", code, r.Code, r.Body.String())
+	}
+	return r
+}
+
+// AssertHeader 断言响应头
+func (r *Response) AssertHeader(key, value string) *Response {
+	r.t.Helper()
+	if got := r.Header().Get(key); got != value {
+		r.t.Fatalf("gorookietest: want header %s %q, got %q", key, value, got)
+	}
+	return r
+}
+
+// AssertBody 断言响应体
+func (r *Response) AssertBody(body string) *Response {
+	r.t.Helper()
+	if got := r.Body.String(); got != body {
+		r.t.Fatalf("gorookietest: want body %q, got %q", body, got)
+	}
+	return r
+}
+
+// AssertBodyContains 断言响应体包含s
+func (r *Response) AssertBodyContains(s string) *Response {
+	r.t.Helper()
+	if !strings.Contains(r.Body.String(), s) {
+		r.t.Fatalf("gorookietest: body %q does not contain %q", r.Body.String(), s)
+	}
+	return r
+}
+
+/**
+ * AssertJSON
+ * @Author：Jack-Z
+ * @Description: 断言响应体与expected的JSON内容一致，忽略字段顺序和空白
+ * @receiver r
+ * @param expected 结构体、map或JSON字符串
+ * @return *Response
+ */
+func (r *Response) AssertJSON(expected any) *Response {
+	r.t.Helper()
+	var want, got any
+	data, ok := expected.(string)
+	if !ok {
+		b, err := json.Marshal(expected)
+		if err != nil {
+			r.t.Fatalf("gorookietest: marshal expected json: %v", err)
+		}
+		data = string(b)
+	}
+	if err := json.Unmarshal([]byte(data), &want); err != nil {
+		r.t.Fatalf("gorookietest: decode expected json: %v", err)
+	}
+	if err := json.Unmarshal(r.Body.Bytes(), &got); err != nil {
+		r.t.Fatalf("gorookietest: decode json body %q: %v", r.Body.String(), err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		r.t.Fatalf("gorookietest: want json %s, got %s", data, r.Body.String())
+	}
+	return r
+}
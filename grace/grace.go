@@ -0,0 +1,275 @@
+package grace
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/**
+ * 热重启：通过Listen打开的监听器在Restart时以文件描述符的形式传给新进程，
+ * 新进程通过Listen拿到同一个socket继续accept，旧进程停止accept并处理完已有连接后退出，
+ * 整个过程中端口不会关闭，新连接在内核队列中等待，不会被拒绝。
+ * 文件描述符的传递方式与systemd的socket activation一致（LISTEN_FDS、LISTEN_FDNAMES，从3开始），
+ * 因此由systemd启动并传入的监听器同样可以通过Listen获取
+ */
+
+const (
+	envListenFds     = "LISTEN_FDS"
+	envListenPid     = "LISTEN_PID"
+	envListenFdNames = "LISTEN_FDNAMES"
+	listenFdsStart   = 3 // 传入的第一个文件描述符
+)
+
+var (
+	mu         sync.Mutex
+	inherited  []net.Listener // 继承的、尚未被Listen取走的监听器
+	active     []*listener    // 当前进程正在使用的监听器
+	inheritErr error
+	inheritOne sync.Once
+	hasParent  bool
+	restarted  = make(chan struct{})
+)
+
+type filer interface {
+	File() (*os.File, error)
+}
+
+/**
+ * listener
+ * @Description: 记录network和addr的监听器，关闭后不再传给新进程
+ */
+type listener struct {
+	net.Listener
+	network string
+	addr    string
+	once    sync.Once
+}
+
+func (l *listener) Close() error {
+	l.once.Do(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for i, item := range active {
+			if item == l {
+				active = append(active[:i], active[i+1:]...)
+				break
+			}
+		}
+	})
+	return l.Listener.Close()
+}
+
+/**
+ * Listen
+ * @Author：Jack-Z
+ * @Description: 打开监听器，优先使用从父进程或systemd继承的同一地址的监听器，
+ * 没有时新建；unix socket新建前会删除上次运行遗留的socket文件
+ * @param network tcp、tcp4、tcp6或unix
+ * @param addr
+ * @return net.Listener
+ * @return error
+ */
+func Listen(network, addr string) (net.Listener, error) {
+	if err := inherit(); err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for i, l := range inherited {
+		if sameAddr(network, addr, l.Addr()) {
+			inherited = append(inherited[:i], inherited[i+1:]...)
+			return track(l, network, addr), nil
+		}
+	}
+	if network == "unix" {
+		if info, err := os.Lstat(addr); err == nil && info.Mode()&os.ModeSocket != 0 {
+			if err := os.Remove(addr); err != nil {
+				return nil, err
+			}
+		}
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return track(l, network, addr), nil
+}
+
+func track(l net.Listener, network, addr string) net.Listener {
+	gl := &listener{Listener: l, network: network, addr: addr}
+	active = append(active, gl)
+	return gl
+}
+
+/**
+ * Inherited
+ * @Author：Jack-Z
+ * @Description: 当前进程是否由Restart或systemd启动并继承了监听器
+ * @return bool
+ */
+func Inherited() bool {
+	_ = inherit()
+	return hasParent
+}
+
+/**
+ * Restart
+ * @Author：Jack-Z
+ * @Description: 以相同的参数启动当前程序的新进程，并把正在使用的监听器传过去；
+ * 调用方随后应停止accept并等待已有连接处理完成再退出。只执行一次，重复调用返回nil
+ * @return error
+ */
+func Restart() error {
+	mu.Lock()
+	defer mu.Unlock()
+	select {
+	case <-restarted:
+		return nil
+	default:
+	}
+
+	files := make([]*os.File, 0, len(active))
+	names := make([]string, 0, len(active))
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for _, l := range active {
+		fl, ok := l.Listener.(filer)
+		if !ok {
+			return fmt.Errorf("listener %s://%s can not be passed to child process", l.network, l.addr)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+		names = append(names, l.network+"@"+l.Addr().String())
+	}
+
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	env := make([]string, 0, len(os.Environ())+2)
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, envListenFds+"=") || strings.HasPrefix(kv, envListenPid+"=") ||
+			strings.HasPrefix(kv, envListenFdNames+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	env = append(env, envListenFds+"="+strconv.Itoa(len(files)), envListenFdNames+"="+strings.Join(names, ":"))
+
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = env
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// socket已交给新进程，旧进程关闭监听器时不能删除socket文件
+	for _, l := range active {
+		if ul, ok := l.Listener.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	close(restarted)
+	return nil
+}
+
+/**
+ * Restarted
+ * @Author：Jack-Z
+ * @Description: Restart成功启动新进程后关闭的channel，服务可以据此开始优雅关闭
+ * @return <-chan struct{}
+ */
+func Restarted() <-chan struct{} {
+	return restarted
+}
+
+/**
+ * IsRestarting
+ * @Author：Jack-Z
+ * @Description: 是否已经启动了新进程，此时旧进程不应注销服务
+ * @return bool
+ */
+func IsRestarting() bool {
+	select {
+	case <-restarted:
+		return true
+	default:
+		return false
+	}
+}
+
+/**
+ * inherit
+ * @Author：Jack-Z
+ * @Description: 读取LISTEN_FDS传入的监听器，只执行一次。
+ * 设置了LISTEN_PID（systemd）但与当前进程不一致时忽略，读取后清除相关环境变量
+ * @return error
+ */
+func inherit() error {
+	inheritOne.Do(func() {
+		count := os.Getenv(envListenFds)
+		if count == "" {
+			return
+		}
+		pid := os.Getenv(envListenPid)
+		defer func() {
+			os.Unsetenv(envListenFds)
+			os.Unsetenv(envListenPid)
+			os.Unsetenv(envListenFdNames)
+		}()
+		if pid != "" && pid != strconv.Itoa(os.Getpid()) {
+			return
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			inheritErr = fmt.Errorf("invalid %s: %s", envListenFds, count)
+			return
+		}
+		for fd := listenFdsStart; fd < listenFdsStart+n; fd++ {
+			f := os.NewFile(uintptr(fd), "listener-"+strconv.Itoa(fd))
+			l, err := net.FileListener(f)
+			f.Close()
+			if err != nil {
+				inheritErr = fmt.Errorf("inherit fd %d: %w", fd, err)
+				return
+			}
+			inherited = append(inherited, l)
+		}
+		hasParent = n > 0
+	})
+	return inheritErr
+}
+
+// sameAddr 判断监听地址是否与要监听的地址一致，未指定IP时与任意IP一致
+func sameAddr(network, addr string, la net.Addr) bool {
+	switch la := la.(type) {
+	case *net.TCPAddr:
+		if !strings.HasPrefix(network, "tcp") {
+			return false
+		}
+		ta, err := net.ResolveTCPAddr(network, addr)
+		if err != nil || ta.Port != la.Port {
+			return false
+		}
+		if ta.IP == nil || ta.IP.IsUnspecified() {
+			return la.IP == nil || la.IP.IsUnspecified()
+		}
+		return ta.IP.Equal(la.IP)
+	case *net.UnixAddr:
+		return network == "unix" && la.Name == addr
+	}
+	return false
+}
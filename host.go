@@ -0,0 +1,136 @@
+package go_rookie
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+/**
+ * hostRouter
+ * @Description: 绑定到域名的路由器，域名规则如“api.example.com”、“*.tenant.example.com”、
+ * “{tenant}.example.com”，“*”和“{name}”（或“:name”）各匹配一级域名
+ */
+type hostRouter struct {
+	pattern string
+	labels  []string // 按“.”拆分后的域名规则
+	statics int      // 静态label数量，越多优先级越高
+	router  *router
+	group   *routerGroup // 根分组
+}
+
+/**
+ * Host
+ * @Author：Jack-Z
+ * @Description: 返回绑定到域名规则的根分组，请求的Host匹配时只在该分组所属的路由器中查找路由，
+ * 未匹配任何域名规则的请求仍由默认路由器（开启网关时由网关）处理。
+ * 多个规则都匹配时，静态label更多的规则优先，其次按注册顺序
+ * @receiver e
+ * @param pattern
+ * @return *routerGroup
+ */
+func (e *Engine) Host(pattern string) *routerGroup {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+	for _, hr := range e.hosts {
+		if hr.pattern == pattern {
+			return hr.group
+		}
+	}
+	hr := &hostRouter{
+		pattern: pattern,
+		labels:  strings.Split(pattern, "."),
+		router: &router{
+			engine:   e,
+			treeNode: &treeNode{},
+			host:     pattern,
+		},
+	}
+	for _, label := range hr.labels {
+		if _, ok := hostParamKey(label); !ok {
+			hr.statics++
+		}
+	}
+	hr.group = hr.router.Group("")
+	e.hosts = append(e.hosts, hr)
+	sort.SliceStable(e.hosts, func(i, j int) bool {
+		return e.hosts[i].statics > e.hosts[j].statics
+	})
+	return hr.group
+}
+
+/**
+ * matchHost
+ * @Author：Jack-Z
+ * @Description: 根据请求的Host查找路由器
+ * @receiver e
+ * @param host 请求的Host，可以带端口
+ * @return *router 未匹配时返回nil
+ * @return map[string]string 域名中捕获的参数
+ */
+func (e *Engine) matchHost(host string) (*router, map[string]string) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	labels := strings.Split(host, ".")
+	for _, hr := range e.hosts {
+		if params, ok := hr.match(labels); ok {
+			return hr.router, params
+		}
+	}
+	return nil, nil
+}
+
+func (hr *hostRouter) match(labels []string) (map[string]string, bool) {
+	if len(labels) != len(hr.labels) {
+		return nil, false
+	}
+	var params map[string]string
+	for i, label := range hr.labels {
+		key, ok := hostParamKey(label)
+		if !ok {
+			if label != labels[i] {
+				return nil, false
+			}
+			continue
+		}
+		if labels[i] == "" {
+			return nil, false
+		}
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[key] = labels[i]
+	}
+	return params, true
+}
+
+// hostParamKey 获取域名label对应的参数名，“{tenant}”和“:tenant”对应“tenant”，“*”对应“*”
+func hostParamKey(label string) (string, bool) {
+	if label == "*" {
+		return label, true
+	}
+	if len(label) > 2 && label[0] == '{' && label[len(label)-1] == '}' {
+		return label[1 : len(label)-1], true
+	}
+	if len(label) > 1 && label[0] == ':' {
+		return label[1:], true
+	}
+	return "", false
+}
+
+/**
+ * allRouters
+ * @Author：Jack-Z
+ * @Description: 默认路由器和所有域名路由器
+ * @receiver e
+ * @return []*router
+ */
+func (e *Engine) allRouters() []*router {
+	routers := make([]*router, 0, len(e.hosts)+1)
+	routers = append(routers, &e.router)
+	for _, hr := range e.hosts {
+		routers = append(routers, hr.router)
+	}
+	return routers
+}
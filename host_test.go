@@ -0,0 +1,47 @@
+package go_rookie_test
+
+import (
+	"github.com/Jack-ZL/go_rookie"
+	"github.com/Jack-ZL/go_rookie/gorookietest"
+	"net/http"
+	"testing"
+)
+
+func TestHostRouting(t *testing.T) {
+	engine := go_rookie.New()
+	engine.Group("").Get("/", func(ctx *go_rookie.Context) {
+		ctx.String(http.StatusOK, "default")
+	})
+	engine.Host("api.example.com").Get("/", func(ctx *go_rookie.Context) {
+		ctx.String(http.StatusOK, "api")
+	})
+	engine.Host("{tenant}.example.com").Group("/users").Get("/:id", func(ctx *go_rookie.Context) {
+		ctx.String(http.StatusOK, ctx.HostParam("tenant")+":"+ctx.Param("id"))
+	})
+	engine.Host("*.tenant.example.com").Get("/", func(ctx *go_rookie.Context) {
+		ctx.String(http.StatusOK, "tenant "+ctx.HostParam("*"))
+	})
+
+	tests := []struct {
+		host   string
+		path   string
+		status int
+		body   string
+	}{
+		{"api.example.com", "/", http.StatusOK, "api"},
+		{"API.example.com:8080", "/", http.StatusOK, "api"},
+		{"acme.example.com", "/users/7", http.StatusOK, "acme:7"},
+		{"shop.tenant.example.com", "/", http.StatusOK, "tenant shop"},
+		// 静态label更多的规则优先
+		{"api.example.com", "/users/7", http.StatusNotFound, ""},
+		// 未匹配任何域名规则时使用默认路由器
+		{"other.org", "/", http.StatusOK, "default"},
+		{"a.b.example.com", "/", http.StatusOK, "default"},
+	}
+	for _, tt := range tests {
+		resp := gorookietest.Get(t, engine, tt.path).Host(tt.host).Do().AssertStatus(tt.status)
+		if tt.body != "" {
+			resp.AssertBody(tt.body)
+		}
+	}
+}
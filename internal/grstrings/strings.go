@@ -37,3 +37,11 @@ func check(v any) string {
 		return fmt.Sprintf("%v", v)
 	}
 }
+
+// MediaType 去掉Content-Type中的参数并转为小写，如“application/json; charset=utf-8”返回“application/json”
+func MediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
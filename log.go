@@ -135,7 +135,7 @@ func LoggingWithConfig(conf LoggingConfig, next HandlerFunc) HandlerFunc {
 		ip, _, _ := net.SplitHostPort(strings.TrimSpace(ctx.R.RemoteAddr))
 		clientIP := net.ParseIP(ip)  // ip地址
 		method := r.Method           // 请求方式
-		statusCode := ctx.StatusCode // 状态码
+		statusCode := ctx.W.Status() // 状态码
 
 		if raw != "" {
 			path = path + "?" + raw
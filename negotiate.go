@@ -0,0 +1,203 @@
+package go_rookie
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Jack-ZL/go_rookie/binding"
+	"github.com/Jack-ZL/go_rookie/internal/grstrings"
+	"github.com/Jack-ZL/go_rookie/render"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrNotAcceptable 请求的Accept中没有服务端可以提供的格式
+var ErrNotAcceptable = errors.New("the accepted formats are not offered by the server")
+
+/**
+ * Negotiate
+ * @Description: 内容协商的配置，各格式的数据为nil时使用Data
+ */
+type Negotiate struct {
+	Offered      []string // 可提供的MIME类型，按服务端的偏好排序
+	Data         any      // 默认数据
+	JSONData     any
+	XMLData      any
+	HTMLName     string // html模板名称，为空时把HTMLData作为html字符串输出
+	HTMLData     any
+	YAMLData     any
+	TOMLData     any
+	MsgPackData  any
+	ProtoBufData any                      // 需要实现proto.Message
+	Renders      map[string]render.Render // 其他格式的Render，key为MIME类型，优先于内置格式
+}
+
+// negotiateRenders 按MIME类型生成Render
+var negotiateRenders = map[string]func(c *Context, config Negotiate) render.Render{
+	binding.MIMEJSON: func(c *Context, config Negotiate) render.Render {
+		return &render.JSON{Data: config.dataOr(config.JSONData)}
+	},
+	binding.MIMEXML: func(c *Context, config Negotiate) render.Render {
+		return &render.XML{Data: config.dataOr(config.XMLData)}
+	},
+	binding.MIMEXML2: func(c *Context, config Negotiate) render.Render {
+		return &render.XML{Data: config.dataOr(config.XMLData)}
+	},
+	binding.MIMEHTML: func(c *Context, config Negotiate) render.Render {
+		data := config.dataOr(config.HTMLData)
+		if config.HTMLName != "" {
+			return &render.HTML{Data: data, IsTemplate: true, Template: c.engine.HTMLRender.Template, Name: config.HTMLName}
+		}
+		return &render.HTML{Data: fmt.Sprint(data)}
+	},
+	binding.MIMEPlain: func(c *Context, config Negotiate) render.Render {
+		return &render.String{Format: fmt.Sprint(config.Data)}
+	},
+	binding.MIMEYAML: func(c *Context, config Negotiate) render.Render {
+		return &render.YAML{Data: config.dataOr(config.YAMLData)}
+	},
+	binding.MIMEYAML2: func(c *Context, config Negotiate) render.Render {
+		return &render.YAML{Data: config.dataOr(config.YAMLData)}
+	},
+	binding.MIMETOML: func(c *Context, config Negotiate) render.Render {
+		return &render.TOML{Data: config.dataOr(config.TOMLData)}
+	},
+	binding.MIMEMsgPack: func(c *Context, config Negotiate) render.Render {
+		return &render.MsgPack{Data: config.dataOr(config.MsgPackData)}
+	},
+	binding.MIMEMsgPack2: func(c *Context, config Negotiate) render.Render {
+		return &render.MsgPack{Data: config.dataOr(config.MsgPackData)}
+	},
+	binding.MIMEProtoBuf: func(c *Context, config Negotiate) render.Render {
+		return &render.ProtoBuf{Data: config.dataOr(config.ProtoBufData)}
+	},
+}
+
+func (n Negotiate) dataOr(data any) any {
+	if data != nil {
+		return data
+	}
+	return n.Data
+}
+
+/**
+ * Negotiate
+ * @Author：Jack-Z
+ * @Description: 根据请求的Accept在config.Offered中选择格式并渲染，
+ * 没有可接受的格式时返回406和ErrNotAcceptable
+ * @receiver c
+ * @param status
+ * @param config
+ * @return error
+ */
+func (c *Context) Negotiate(status int, config Negotiate) error {
+	c.W.Header().Add("Vary", "Accept")
+	format := c.NegotiateFormat(config.Offered...)
+	if format == "" {
+		c.AbortWithStatus(http.StatusNotAcceptable)
+		return ErrNotAcceptable
+	}
+	if r, ok := config.Renders[format]; ok {
+		return c.Render(status, r)
+	}
+	newRender, ok := negotiateRenders[format]
+	if !ok {
+		return fmt.Errorf("no render for offered format [%s]", format)
+	}
+	return c.Render(status, newRender(c, config))
+}
+
+/**
+ * NegotiateFormat
+ * @Author：Jack-Z
+ * @Description: 根据请求的Accept（含q值）选择最合适的格式，q值相同时按offered的顺序，
+ * 没有Accept时返回第一个
+ * @receiver c
+ * @param offered 可提供的MIME类型
+ * @return string 没有可接受的格式时返回空字符串
+ */
+func (c *Context) NegotiateFormat(offered ...string) string {
+	if len(offered) == 0 {
+		return ""
+	}
+	accepts := parseAccept(c.R.Header.Values("Accept"))
+	if len(accepts) == 0 {
+		return offered[0]
+	}
+	best, bestQ := "", 0.0
+	for _, offer := range offered {
+		if q := acceptQuality(accepts, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+/**
+ * ContentType
+ * @Author：Jack-Z
+ * @Description: 请求的Content-Type，不含参数
+ * @receiver c
+ * @return string
+ */
+func (c *Context) ContentType() string {
+	return grstrings.MediaType(c.R.Header.Get("Content-Type"))
+}
+
+type acceptRange struct {
+	typ     string // 主类型，如“application”，可以是“*”
+	subtype string // 子类型，如“json”，可以是“*”
+	q       float64
+}
+
+// parseAccept 解析Accept请求头，忽略格式错误的项
+func parseAccept(values []string) []acceptRange {
+	var accepts []acceptRange
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			params := strings.Split(part, ";")
+			mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+			typ, subtype, ok := strings.Cut(mediaType, "/")
+			if !ok || typ == "" || subtype == "" {
+				continue
+			}
+			accept := acceptRange{typ: typ, subtype: subtype, q: 1}
+			for _, param := range params[1:] {
+				key, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if strings.ToLower(key) != "q" {
+					continue
+				}
+				q, err := strconv.ParseFloat(val, 64)
+				if err != nil || q < 0 || q > 1 {
+					q = 0
+				}
+				accept.q = q
+			}
+			accepts = append(accepts, accept)
+		}
+	}
+	return accepts
+}
+
+// acceptQuality 按最具体的匹配项计算offer的q值，“type/subtype” > “type/*” > “*/*”
+func acceptQuality(accepts []acceptRange, offer string) float64 {
+	typ, subtype, _ := strings.Cut(strings.ToLower(offer), "/")
+	q, specificity := 0.0, -1
+	for _, accept := range accepts {
+		var s int
+		switch {
+		case accept.typ == typ && accept.subtype == subtype:
+			s = 2
+		case accept.typ == typ && accept.subtype == "*":
+			s = 1
+		case accept.typ == "*" && accept.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = accept.q, s
+		}
+	}
+	return q
+}
@@ -0,0 +1,98 @@
+package go_rookie
+
+import (
+	"github.com/Jack-ZL/go_rookie/binding"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	offered := []string{"application/json", "application/xml", "text/html"}
+	cases := map[string]string{
+		"":                                       "application/json",
+		"application/xml":                        "application/xml",
+		"text/html;q=0.9, application/xml;q=0.5": "text/html",
+		"text/*":                                 "text/html",
+		"*/*":                                    "application/json",
+		"image/png":                              "",
+		"application/*;q=0.2, application/json;q=0": "application/xml",
+	}
+	engine := New()
+	for accept, want := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		ctx := engine.NewContext(httptest.NewRecorder(), r)
+		if got := ctx.NegotiateFormat(offered...); got != want {
+			t.Errorf("Accept %q: want %q, got %q", accept, want, got)
+		}
+	}
+}
+
+func TestNegotiateRoundTrip(t *testing.T) {
+	type config struct {
+		Name string `yaml:"name" toml:"name" msgpack:"name"`
+		Port int    `yaml:"port" toml:"port" msgpack:"port"`
+	}
+	engine := New()
+	offered := []string{binding.MIMEJSON, binding.MIMEYAML, binding.MIMETOML, binding.MIMEMsgPack}
+	for _, format := range offered[1:] {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", format)
+		w := httptest.NewRecorder()
+		ctx := engine.NewContext(w, r)
+		if err := ctx.Negotiate(200, Negotiate{Offered: offered, Data: config{Name: "api", Port: 8080}}); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		ctx.W.WriteHeaderNow()
+		if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, format) {
+			t.Fatalf("%s: unexpected content type %q", format, got)
+		}
+
+		r = httptest.NewRequest("POST", "/", w.Body)
+		r.Header.Set("Content-Type", format)
+		var got config
+		if err := engine.NewContext(httptest.NewRecorder(), r).ShouldBind(&got); err != nil || got.Name != "api" || got.Port != 8080 {
+			t.Fatalf("%s: unexpected result %+v %v", format, got, err)
+		}
+	}
+}
+
+func TestNegotiateProtoBuf(t *testing.T) {
+	engine := New()
+	offered := []string{binding.MIMEJSON, binding.MIMEProtoBuf}
+	negotiate := func(data any) (*httptest.ResponseRecorder, error) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", binding.MIMEProtoBuf)
+		w := httptest.NewRecorder()
+		err := engine.NewContext(w, r).Negotiate(200, Negotiate{Offered: offered, ProtoBufData: data, Data: "text"})
+		return w, err
+	}
+	bind := func(body []byte, obj any) error {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(string(body)))
+		r.Header.Set("Content-Type", binding.MIMEProtoBuf)
+		return engine.NewContext(httptest.NewRecorder(), r).ShouldBind(obj)
+	}
+
+	w, err := negotiate(wrapperspb.String("api"))
+	if err != nil || w.Header().Get("Content-Type") != binding.MIMEProtoBuf {
+		t.Fatalf("unexpected response %v %v", w.Header(), err)
+	}
+	var got wrapperspb.StringValue
+	if err := bind(w.Body.Bytes(), &got); err != nil || !proto.Equal(&got, wrapperspb.String("api")) {
+		t.Fatalf("unexpected result %v %v", got.GetValue(), err)
+	}
+
+	// 不是proto.Message时渲染和绑定都返回错误
+	if w, err := negotiate(struct{ Name string }{"api"}); err == nil || w.Body.Len() != 0 {
+		t.Fatalf("expected render error, got %q", w.Body.String())
+	}
+	var plain struct{ Name string }
+	if err := bind(w.Body.Bytes(), &plain); err == nil {
+		t.Fatal("expected binding error")
+	}
+}
@@ -879,7 +879,8 @@ func (s *GrSession) QueryExec(query string, values ...any) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	exec, err := prepare.Exec(values)
+	defer prepare.Close()
+	exec, err := prepare.Exec(values...)
 	if err != nil {
 		return 0, err
 	}
@@ -908,10 +909,12 @@ func (s *GrSession) QueryRow(sql string, data any, queryValues ...any) error {
 	if err != nil {
 		return err
 	}
+	defer stmt.Close()
 	rows, err := stmt.Query(queryValues...)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 
 	columns, err := rows.Columns()
 	if err != nil {
@@ -46,6 +46,26 @@ func (r *GrEtcdRegister) RegisterService(serviceName string, host string, port i
 	return err
 }
 
+/**
+ * DeregisterService
+ * @Author：Jack-Z
+ * @Description: 依据服务名称注销服务，只删除仍指向当前实例的记录
+ * @receiver r
+ * @param serviceName
+ * @param host
+ * @param port
+ * @return error
+ */
+func (r *GrEtcdRegister) DeregisterService(serviceName string, host string, port int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := r.cli.Txn(ctx).
+		If(clientv3.Compare(clientv3.Value(serviceName), "=", fmt.Sprintf("%s:%d", host, port))).
+		Then(clientv3.OpDelete(serviceName)).
+		Commit()
+	return err
+}
+
 /**
  * GetValue
  * @Author：Jack-Z
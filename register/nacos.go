@@ -71,6 +71,26 @@ func (r *GrNacosRegister) RegisterService(serviceName string, host string, port
 	return err
 }
 
+/**
+ * DeregisterService
+ * @Author：Jack-Z
+ * @Description: 注销服务
+ * @receiver r
+ * @param serviceName
+ * @param host
+ * @param port
+ * @return error
+ */
+func (r *GrNacosRegister) DeregisterService(serviceName string, host string, port int) error {
+	_, err := r.cli.DeregisterInstance(vo.DeregisterInstanceParam{
+		Ip:          host,
+		Port:        uint64(port),
+		ServiceName: serviceName,
+		Ephemeral:   true,
+	})
+	return err
+}
+
 /**
  * GetValue
  * @Author：Jack-Z
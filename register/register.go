@@ -11,6 +11,7 @@ type Option struct {
 	ServiceName       string        // 服务名称
 	Host              string        // 域名
 	Port              int           // 端口号
+	AutoRegister      bool          // Engine启动时是否自动注册ServiceName，默认需要手动调用RegisterService
 	NacosServerConfig []constant.ServerConfig
 	NacosClientConfig *constant.ClientConfig
 }
@@ -21,3 +22,8 @@ type GrRegister interface {
 	GetValue(serviceName string) (string, error)                     //通过服务名称获取一个实例
 	Close() error                                                    //关闭客户端
 }
+
+// GrDeregister 支持注销服务的注册客户端，Engine关闭时会通过它注销服务
+type GrDeregister interface {
+	DeregisterService(serviceName string, host string, port int) error //通过名称注销服务
+}
@@ -1,8 +1,12 @@
 package render
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
 )
 
 type JSON struct {
@@ -38,3 +42,173 @@ func (j *JSON) Render(w http.ResponseWriter, code int) error {
 func (j *JSON) WriteContentType(w http.ResponseWriter) {
 	writeContentType(w, "application/json; charset=utf-8")
 }
+
+// DefaultSecureJSONPrefix SecureJSON默认的前缀，使响应不能作为<script>直接执行
+const DefaultSecureJSONPrefix = "while(1);"
+
+// ErrInvalidCallback JSONP的回调函数名不合法
+var ErrInvalidCallback = errors.New("invalid jsonp callback")
+
+// callbackPattern 合法的回调函数名，如“cb”、“jQuery123_456”、“app.handlers.cb”
+var callbackPattern = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*(\.[a-zA-Z_$][a-zA-Z0-9_$]*)*$`)
+
+/**
+ * JSONP
+ * @Description: 以“callback(data);”的形式输出，Callback为空时按普通json输出
+ */
+type JSONP struct {
+	Callback string
+	Data     any
+}
+
+/**
+ * Render
+ * @Author：Jack-Z
+ * @Description: 校验回调函数名后输出，函数名不合法时返回ErrInvalidCallback且不写入响应
+ * @receiver j
+ * @param w
+ * @param code
+ * @return error
+ */
+func (j *JSONP) Render(w http.ResponseWriter, code int) error {
+	if j.Callback == "" {
+		return (&JSON{Data: j.Data}).Render(w, code)
+	}
+	if len(j.Callback) > 128 || !callbackPattern.MatchString(j.Callback) {
+		return ErrInvalidCallback
+	}
+	jsonData, err := json.Marshal(j.Data)
+	if err != nil {
+		return err
+	}
+	j.WriteContentType(w)
+	// 防止浏览器把响应当作其他类型解析
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	// 开头的注释避免回调函数名与前面的内容拼接（Rosetta Flash）
+	_, err = fmt.Fprintf(w, "/**/ typeof %s === 'function' && %s(%s);", j.Callback, j.Callback, jsonData)
+	return err
+}
+
+func (j *JSONP) WriteContentType(w http.ResponseWriter) {
+	if j.Callback == "" {
+		writeContentType(w, "application/json; charset=utf-8")
+		return
+	}
+	writeContentType(w, "application/javascript; charset=utf-8")
+}
+
+/**
+ * SecureJSON
+ * @Description: 在json前加上Prefix，防止json数组被<script>引用劫持
+ */
+type SecureJSON struct {
+	Prefix string // 为空时使用DefaultSecureJSONPrefix
+	Data   any
+}
+
+func (s *SecureJSON) Render(w http.ResponseWriter, code int) error {
+	jsonData, err := json.Marshal(s.Data)
+	if err != nil {
+		return err
+	}
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = DefaultSecureJSONPrefix
+	}
+	s.WriteContentType(w)
+	w.WriteHeader(code)
+	if _, err = w.Write([]byte(prefix)); err != nil {
+		return err
+	}
+	_, err = w.Write(jsonData)
+	return err
+}
+
+func (s *SecureJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/json; charset=utf-8")
+}
+
+/**
+ * AsciiJSON
+ * @Description: 非ASCII字符转义为\uXXXX输出，适用于不能正确处理utf-8的客户端
+ */
+type AsciiJSON struct {
+	Data any
+}
+
+func (a *AsciiJSON) Render(w http.ResponseWriter, code int) error {
+	jsonData, err := json.Marshal(a.Data)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, r := range string(jsonData) {
+		switch {
+		case r < 0x80:
+			buf.WriteRune(r)
+		case r > 0xffff:
+			// 超出基本平面的字符使用代理对
+			r -= 0x10000
+			fmt.Fprintf(&buf, `\u%04x\u%04x`, 0xd800+(r>>10), 0xdc00+(r&0x3ff))
+		default:
+			fmt.Fprintf(&buf, `\u%04x`, r)
+		}
+	}
+	a.WriteContentType(w)
+	w.WriteHeader(code)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func (a *AsciiJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/json")
+}
+
+/**
+ * PureJSON
+ * @Description: 不转义<、>、&等html字符，原样输出
+ */
+type PureJSON struct {
+	Data any
+}
+
+func (p *PureJSON) Render(w http.ResponseWriter, code int) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(p.Data); err != nil {
+		return err
+	}
+	p.WriteContentType(w)
+	w.WriteHeader(code)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *PureJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/json; charset=utf-8")
+}
+
+/**
+ * IndentedJSON
+ * @Description: 缩进格式的json，便于调试时阅读
+ */
+type IndentedJSON struct {
+	Data any
+}
+
+func (i *IndentedJSON) Render(w http.ResponseWriter, code int) error {
+	jsonData, err := json.MarshalIndent(i.Data, "", "    ")
+	if err != nil {
+		return err
+	}
+	i.WriteContentType(w)
+	w.WriteHeader(code)
+	_, err = w.Write(jsonData)
+	return err
+}
+
+func (i *IndentedJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/json; charset=utf-8")
+}
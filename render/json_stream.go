@@ -0,0 +1,110 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// streamFlushCount 流式输出时每写入多少个元素flush一次
+const streamFlushCount = 1000
+
+// ErrStreamInterrupted Done在channel关闭之前被关闭，输出的json数组没有结束
+var ErrStreamInterrupted = errors.New("json stream: interrupted before the channel was closed")
+
+/**
+ * JSONStream
+ * @Description: 逐个元素编码并输出json数组，不需要把整个数组编码到内存中。
+ * Data为切片、数组或可接收的channel，channel关闭时数组结束
+ */
+type JSONStream struct {
+	Data any
+	Done <-chan struct{} // 可选，关闭时停止读取channel并返回ErrStreamInterrupted，如请求的Context().Done()
+}
+
+/**
+ * Render
+ * @Author：Jack-Z
+ * @Description: 写出“[”后逐个写入元素，每streamFlushCount个元素flush一次。
+ * 状态码在写入第一个字节前确定，之后元素编码失败或Done被关闭时不再写出“]”，
+ * 客户端可以据此判断数组不完整
+ * @receiver s
+ * @param w
+ * @param code
+ * @return error
+ */
+func (s *JSONStream) Render(w http.ResponseWriter, code int) error {
+	rv := reflect.ValueOf(s.Data)
+	next, err := s.iterator(rv)
+	if err != nil {
+		return err
+	}
+	s.WriteContentType(w)
+	w.WriteHeader(code)
+	flusher, _ := w.(http.Flusher)
+
+	if _, err := w.Write([]byte{'['}); err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	for i := 0; ; i++ {
+		elem, ok, err := next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if i > 0 {
+			if _, err := w.Write([]byte{','}); err != nil {
+				return err
+			}
+		}
+		// Encode会在末尾追加换行，作为空白字符不影响json数组的解析
+		if err := encoder.Encode(elem.Interface()); err != nil {
+			return err
+		}
+		if flusher != nil && (i+1)%streamFlushCount == 0 {
+			flusher.Flush()
+		}
+	}
+	_, err = w.Write([]byte{']'})
+	return err
+}
+
+// iterator 返回依次取出元素的函数，没有更多元素时返回false，Done被关闭时返回ErrStreamInterrupted
+func (s *JSONStream) iterator(rv reflect.Value) (func() (reflect.Value, bool, error), error) {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		i := 0
+		return func() (reflect.Value, bool, error) {
+			if i >= rv.Len() {
+				return reflect.Value{}, false, nil
+			}
+			i++
+			return rv.Index(i - 1), true, nil
+		}, nil
+	case reflect.Chan:
+		if rv.Type().ChanDir()&reflect.RecvDir == 0 {
+			return nil, errors.New("json stream: channel is send-only")
+		}
+		cases := []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: rv}}
+		if s.Done != nil {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.Done)})
+		}
+		return func() (reflect.Value, bool, error) {
+			chosen, elem, ok := reflect.Select(cases)
+			if chosen != 0 {
+				return reflect.Value{}, false, ErrStreamInterrupted
+			}
+			return elem, ok, nil
+		}, nil
+	}
+	return nil, fmt.Errorf("json stream: unsupported type %T", s.Data)
+}
+
+func (s *JSONStream) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/json; charset=utf-8")
+}
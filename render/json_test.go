@@ -0,0 +1,74 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJSONModes(t *testing.T) {
+	data := map[string]string{"html": "<b>&</b>", "text": "中文😀"}
+	cases := []struct {
+		render Render
+		want   string
+	}{
+		{&JSONP{Callback: "app.cb", Data: []int{1}}, "/**/ typeof app.cb === 'function' && app.cb([1]);"},
+		{&SecureJSON{Data: []int{1}}, "while(1);[1]"},
+		{&AsciiJSON{Data: data}, `{"html":"\u003cb\u003e\u0026\u003c/b\u003e","text":"\u4e2d\u6587\ud83d\ude00"}`},
+		{&PureJSON{Data: data}, `{"html":"<b>&</b>","text":"中文😀"}` + "\n"},
+		{&IndentedJSON{Data: []int{1}}, "[\n    1\n]"},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		if err := c.render.Render(w, 200); err != nil {
+			t.Fatal(err)
+		}
+		if got := w.Body.String(); got != c.want {
+			t.Errorf("%T: want %q, got %q", c.render, c.want, got)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	if err := (&JSONP{Callback: "alert(1)//", Data: 1}).Render(w, 200); err != ErrInvalidCallback || w.Body.Len() != 0 {
+		t.Fatalf("unexpected result %v %q", err, w.Body.String())
+	}
+}
+
+func TestJSONStream(t *testing.T) {
+	ch := make(chan map[string]int)
+	go func() {
+		for i := 0; i < 2500; i++ {
+			ch <- map[string]int{"id": i}
+		}
+		close(ch)
+	}()
+	for _, data := range []any{[]int{}, []int{1, 2, 3}, ch} {
+		w := httptest.NewRecorder()
+		if err := (&JSONStream{Data: data}).Render(w, 200); err != nil {
+			t.Fatal(err)
+		}
+		var got []any
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("invalid json %q: %v", w.Body.String(), err)
+		}
+		if data, ok := data.([]int); ok && len(got) != len(data) {
+			t.Fatalf("unexpected length %d", len(got))
+		} else if !ok && len(got) != 2500 {
+			t.Fatalf("unexpected length %d", len(got))
+		}
+	}
+}
+
+func TestJSONStreamInterrupted(t *testing.T) {
+	ch := make(chan int, 1)
+	ch <- 1
+	done := make(chan struct{})
+	close(done)
+	w := httptest.NewRecorder()
+	// 缓冲中的元素和done同时就绪时select随机选择，结果不能是完整的数组
+	err := (&JSONStream{Data: ch, Done: done}).Render(w, 200)
+	if !errors.Is(err, ErrStreamInterrupted) || json.Valid(w.Body.Bytes()) {
+		t.Fatalf("unexpected result %q %v", w.Body.String(), err)
+	}
+}
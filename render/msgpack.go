@@ -0,0 +1,34 @@
+package render
+
+import (
+	"github.com/vmihailenco/msgpack/v5"
+	"net/http"
+)
+
+type MsgPack struct {
+	Data any
+}
+
+/**
+ * Render
+ * @Author：Jack-Z
+ * @Description: MessagePack数据渲染，字段名使用msgpack tag
+ * @receiver m
+ * @param w
+ * @param code
+ * @return error
+ */
+func (m *MsgPack) Render(w http.ResponseWriter, code int) error {
+	data, err := msgpack.Marshal(m.Data)
+	if err != nil {
+		return err
+	}
+	m.WriteContentType(w)
+	w.WriteHeader(code)
+	_, err = w.Write(data)
+	return err
+}
+
+func (m *MsgPack) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/msgpack")
+}
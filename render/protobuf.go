@@ -0,0 +1,39 @@
+package render
+
+import (
+	"errors"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+)
+
+type ProtoBuf struct {
+	Data any // 需要实现proto.Message
+}
+
+/**
+ * Render
+ * @Author：Jack-Z
+ * @Description: protobuf数据渲染
+ * @receiver p
+ * @param w
+ * @param code
+ * @return error
+ */
+func (p *ProtoBuf) Render(w http.ResponseWriter, code int) error {
+	message, ok := p.Data.(proto.Message)
+	if !ok {
+		return errors.New("protobuf render: data is not a proto.Message")
+	}
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	p.WriteContentType(w)
+	w.WriteHeader(code)
+	_, err = w.Write(data)
+	return err
+}
+
+func (p *ProtoBuf) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/x-protobuf")
+}
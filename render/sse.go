@@ -0,0 +1,108 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/**
+ * SSEvent
+ * @Description: Server-Sent Events的一条事件，Data为string或[]byte时原样输出，其他类型编码为json
+ */
+type SSEvent struct {
+	Id      string // 事件id，客户端重连时通过Last-Event-ID带回
+	Event   string // 事件名称，为空时客户端按message处理
+	Retry   uint   // 客户端重连间隔（毫秒），为0时不设置
+	Data    any
+	Comment string // 注释行，客户端会忽略，可用作心跳
+}
+
+/**
+ * Render
+ * @Author：Jack-Z
+ * @Description: 按text/event-stream格式输出事件
+ * @receiver s
+ * @param w
+ * @param code
+ * @return error
+ */
+func (s *SSEvent) Render(w http.ResponseWriter, code int) error {
+	s.WriteContentType(w)
+	w.WriteHeader(code)
+	return s.Encode(w)
+}
+
+/**
+ * Encode
+ * @Author：Jack-Z
+ * @Description: 把事件编码写入w，多行数据拆分为多个data行
+ * @receiver s
+ * @param w
+ * @return error
+ */
+func (s *SSEvent) Encode(w io.Writer) error {
+	var sb strings.Builder
+	if s.Comment != "" {
+		for _, line := range splitLines(s.Comment) {
+			sb.WriteString(": " + line + "\n")
+		}
+	}
+	if s.Id != "" {
+		sb.WriteString("id: " + removeNewlines(s.Id) + "\n")
+	}
+	if s.Event != "" {
+		sb.WriteString("event: " + removeNewlines(s.Event) + "\n")
+	}
+	if s.Retry > 0 {
+		sb.WriteString("retry: " + strconv.FormatUint(uint64(s.Retry), 10) + "\n")
+	}
+	if s.Data != nil {
+		var data string
+		switch v := s.Data.(type) {
+		case string:
+			data = v
+		case []byte:
+			data = string(v)
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			data = string(b)
+		}
+		for _, line := range splitLines(data) {
+			sb.WriteString("data: " + line + "\n")
+		}
+	}
+	sb.WriteString("\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+/**
+ * WriteContentType
+ * @Author：Jack-Z
+ * @Description: 设置content-type，并禁止缓存和代理缓冲
+ * @receiver s
+ * @param w
+ */
+func (s *SSEvent) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if header.Get("Content-Type") == "text/event-stream" {
+		return
+	}
+	writeContentType(w, "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+func removeNewlines(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
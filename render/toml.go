@@ -0,0 +1,35 @@
+package render
+
+import (
+	"bytes"
+	"github.com/BurntSushi/toml"
+	"net/http"
+)
+
+type TOML struct {
+	Data any // 需要是结构体或map
+}
+
+/**
+ * Render
+ * @Author：Jack-Z
+ * @Description: toml数据渲染
+ * @receiver t
+ * @param w
+ * @param code
+ * @return error
+ */
+func (t *TOML) Render(w http.ResponseWriter, code int) error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(t.Data); err != nil {
+		return err
+	}
+	t.WriteContentType(w)
+	w.WriteHeader(code)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (t *TOML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/toml; charset=utf-8")
+}
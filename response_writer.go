@@ -0,0 +1,152 @@
+package go_rookie
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+const noWritten = -1 // 响应头尚未写出时的size
+
+/**
+ * ResponseWriter
+ * @Description: Context.W的类型，在http.ResponseWriter的基础上记录状态码、响应大小和响应头是否已写出，
+ * 并透传Flush、Hijack、Push
+ */
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	http.Pusher
+	Status() int                      // 响应状态码，未设置时为200
+	Size() int                        // 已写出的响应体字节数，响应头未写出时为-1
+	Written() bool                    // 响应头是否已写出
+	WriteHeaderNow()                  // 立即写出响应头
+	Before(fn func(w ResponseWriter)) // 注册在写出响应头之前执行的钩子，可以用来补充响应头
+	Unwrap() http.ResponseWriter      // 原始的http.ResponseWriter，供http.ResponseController使用
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int
+	beforeFuncs []func(w ResponseWriter)
+}
+
+func (w *responseWriter) reset(writer http.ResponseWriter) {
+	w.ResponseWriter = writer
+	w.status = http.StatusOK
+	w.size = noWritten
+	w.beforeFuncs = nil
+}
+
+/**
+ * WriteHeader
+ * @Author：Jack-Z
+ * @Description: 只记录状态码，在第一次写入响应体或处理结束时才真正写出，
+ * 因此写出之前可以多次修改；写出之后的调用直接忽略
+ * @receiver w
+ * @param code
+ */
+func (w *responseWriter) WriteHeader(code int) {
+	if code > 0 && !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	if w.Written() {
+		return
+	}
+	w.size = 0
+	// 钩子中可能再次注册钩子，按注册顺序执行
+	for i := 0; i < len(w.beforeFuncs); i++ {
+		w.beforeFuncs[i](w)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	w.WriteHeaderNow()
+	n, err := io.WriteString(w.ResponseWriter, s)
+	w.size += n
+	return n, err
+}
+
+// ReadFrom 保留底层的io.ReaderFrom，输出文件时可以使用sendfile
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.WriteHeaderNow()
+	var n int64
+	var err error
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(w.ResponseWriter, r)
+	}
+	w.size += int(n)
+	return n, err
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.size != noWritten
+}
+
+func (w *responseWriter) Before(fn func(w ResponseWriter)) {
+	w.beforeFuncs = append(w.beforeFuncs, fn)
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+/**
+ * Hijack
+ * @Author：Jack-Z
+ * @Description: 接管底层连接，如websocket，接管后不再写出响应头
+ * @receiver w
+ * @return net.Conn
+ * @return *bufio.ReadWriter
+ * @return error
+ */
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil && w.size < 0 {
+		w.size = 0
+	}
+	return conn, rw, err
+}
+
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
@@ -0,0 +1,79 @@
+package go_rookie
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// hijackRecorder 支持Hijack的ResponseRecorder，err不为空时接管失败
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	err error
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	server, client := net.Pipe()
+	client.Close()
+	return server, nil, nil
+}
+
+func TestResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	var w responseWriter
+	w.reset(rec)
+	if w.Written() || w.Size() != noWritten || w.Status() != http.StatusOK {
+		t.Fatalf("unexpected initial state %d %d", w.Status(), w.Size())
+	}
+
+	// 写出之前可以多次修改状态码，钩子在写出响应头之前执行
+	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusAccepted)
+	w.Before(func(w ResponseWriter) {
+		w.Header().Set("X-Status", http.StatusText(w.Status()))
+	})
+	w.Write([]byte("hello"))
+	w.WriteString(" world")
+	w.WriteHeader(http.StatusTeapot)
+	if rec.Code != http.StatusAccepted || w.Status() != http.StatusAccepted || w.Size() != 11 || rec.Header().Get("X-Status") != "Accepted" {
+		t.Fatalf("unexpected response %d %d %d %v", rec.Code, w.Status(), w.Size(), rec.Header())
+	}
+
+	// Flush写出响应头
+	rec = httptest.NewRecorder()
+	w.reset(rec)
+	w.WriteHeader(http.StatusNoContent)
+	w.Flush()
+	if !rec.Flushed || rec.Code != http.StatusNoContent || !w.Written() || w.Size() != 0 {
+		t.Fatalf("unexpected flush %v %d %d", rec.Flushed, rec.Code, w.Size())
+	}
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	tests := []struct {
+		name    string
+		writer  http.ResponseWriter
+		written bool
+	}{
+		{"unsupported", httptest.NewRecorder(), false},
+		{"failed", &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), err: errors.New("hijack failed")}, false},
+		{"ok", &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}, true},
+	}
+	for _, tt := range tests {
+		var w responseWriter
+		w.reset(tt.writer)
+		conn, _, err := w.Hijack()
+		if (err == nil) != tt.written || w.Written() != tt.written {
+			t.Errorf("%s: unexpected result err=%v written=%v", tt.name, err, w.Written())
+		}
+		if conn != nil {
+			conn.Close()
+		}
+	}
+}
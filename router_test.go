@@ -0,0 +1,174 @@
+package go_rookie_test
+
+import (
+	"github.com/Jack-ZL/go_rookie"
+	"github.com/Jack-ZL/go_rookie/gorookietest"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// trace 返回记录执行顺序的中间件，每个中间件在处理前后各记录一次
+func trace(steps *[]string, name string) go_rookie.MiddlewareFunc {
+	return func(next go_rookie.HandlerFunc) go_rookie.HandlerFunc {
+		return func(ctx *go_rookie.Context) {
+			*steps = append(*steps, name)
+			next(ctx)
+			*steps = append(*steps, "/"+name)
+		}
+	}
+}
+
+func TestNestedGroups(t *testing.T) {
+	var steps []string
+	engine := go_rookie.New()
+	api := engine.Group("/api")
+	api.Use(trace(&steps, "api"))
+	v1 := api.Group("/v1")
+	users := v1.Group("/users")
+	users.Get("/:id", func(ctx *go_rookie.Context) {
+		steps = append(steps, "handler")
+		ctx.String(http.StatusOK, ctx.Param("id"))
+	}, trace(&steps, "route"))
+	// 路由注册之后添加的全局和父分组中间件同样生效
+	v1.Use(trace(&steps, "v1"))
+	engine.Use(trace(&steps, "global"))
+
+	tests := []struct {
+		path   string
+		status int
+		steps  string
+	}{
+		{"/api/v1/users/7", http.StatusOK, "global api v1 route handler /route /v1 /api /global"},
+		{"/api/users/7", http.StatusNotFound, "global /global"},
+		{"/v1/users/7", http.StatusNotFound, "global /global"},
+	}
+	for _, tt := range tests {
+		steps = nil
+		gorookietest.Get(t, engine, tt.path).Do().AssertStatus(tt.status)
+		if got := strings.Join(steps, " "); got != tt.steps {
+			t.Errorf("%s: want steps %q, got %q", tt.path, tt.steps, got)
+		}
+	}
+}
+
+func TestNoRouteAndNoMethod(t *testing.T) {
+	engine := go_rookie.New()
+	engine.Use(func(next go_rookie.HandlerFunc) go_rookie.HandlerFunc {
+		return func(ctx *go_rookie.Context) {
+			ctx.W.Header().Set("X-Middleware", "1")
+			next(ctx)
+		}
+	})
+	engine.NoRoute(func(ctx *go_rookie.Context) {
+		ctx.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	})
+	group := engine.Group("/user")
+	group.Get("/:id", func(ctx *go_rookie.Context) {
+		ctx.String(http.StatusOK, "get")
+	})
+	group.Post("/:id", func(ctx *go_rookie.Context) {
+		ctx.String(http.StatusOK, "post")
+	})
+	group.Put("/:id/avatar", func(ctx *go_rookie.Context) {})
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		allow  string
+		body   string
+	}{
+		{http.MethodGet, "/user/1", http.StatusOK, "", "get"},
+		{http.MethodHead, "/user/1", http.StatusOK, "", "get"},
+		{http.MethodDelete, "/user/1", http.StatusMethodNotAllowed, "GET, POST, HEAD, OPTIONS", "/user/1 DELETE not allowed \n"},
+		{http.MethodOptions, "/user/1", http.StatusNoContent, "GET, POST, HEAD, OPTIONS", ""},
+		{http.MethodGet, "/user/1/avatar", http.StatusMethodNotAllowed, "PUT, OPTIONS", "/user/1/avatar GET not allowed \n"},
+		{http.MethodGet, "/order/1", http.StatusNotFound, "", `{"error":"not found"}`},
+	}
+	for _, tt := range tests {
+		resp := gorookietest.NewRequest(t, engine, tt.method, tt.path).Do()
+		resp.AssertStatus(tt.status).AssertHeader("Allow", tt.allow).AssertHeader("X-Middleware", "1")
+		if got := strings.TrimSpace(resp.BodyString()); got != strings.TrimSpace(tt.body) {
+			t.Errorf("%s %s: want body %q, got %q", tt.method, tt.path, tt.body, got)
+		}
+	}
+}
+
+func TestHandlerChain(t *testing.T) {
+	var steps []string
+	tests := []struct {
+		name   string
+		middle []go_rookie.HandlerFunc
+		status int
+		steps  string
+	}{
+		{
+			name: "next",
+			middle: []go_rookie.HandlerFunc{
+				func(ctx *go_rookie.Context) {
+					steps = append(steps, "a")
+					ctx.Next()
+					steps = append(steps, "/a")
+				},
+				func(ctx *go_rookie.Context) {
+					// 不调用Next时执行完自动进入下一个处理函数
+					steps = append(steps, "b")
+				},
+			},
+			status: http.StatusOK,
+			steps:  "a b handler /a",
+		},
+		{
+			name: "abort",
+			middle: []go_rookie.HandlerFunc{
+				func(ctx *go_rookie.Context) {
+					steps = append(steps, "a")
+					ctx.AbortWithStatusJSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+					if !ctx.IsAborted() {
+						t.Error("expected aborted")
+					}
+				},
+				func(ctx *go_rookie.Context) {
+					steps = append(steps, "b")
+				},
+			},
+			status: http.StatusUnauthorized,
+			steps:  "a",
+		},
+	}
+	for _, tt := range tests {
+		steps = nil
+		engine := go_rookie.New()
+		engine.UseHandler(tt.middle...)
+		engine.Group("").Get("/", func(ctx *go_rookie.Context) {
+			steps = append(steps, "handler")
+			ctx.String(http.StatusOK, "ok")
+		})
+		gorookietest.Get(t, engine, "/").Do().AssertStatus(tt.status)
+		if got := strings.Join(steps, " "); got != tt.steps {
+			t.Errorf("%s: want steps %q, got %q", tt.name, tt.steps, got)
+		}
+	}
+}
+
+func TestMiddlewareWithoutNext(t *testing.T) {
+	var steps []string
+	engine := go_rookie.New()
+	engine.Use(trace(&steps, "global"))
+	// 没有调用next的MiddlewareFunc中断处理链，外层中间件的后置逻辑照常执行
+	engine.Use(func(next go_rookie.HandlerFunc) go_rookie.HandlerFunc {
+		return func(ctx *go_rookie.Context) {
+			steps = append(steps, "deny")
+			ctx.String(http.StatusForbidden, "forbidden")
+		}
+	})
+	engine.Group("").Get("/", func(ctx *go_rookie.Context) {
+		steps = append(steps, "handler")
+	}, trace(&steps, "route"))
+
+	gorookietest.Get(t, engine, "/").Do().AssertStatus(http.StatusForbidden).AssertBody("forbidden")
+	if got := strings.Join(steps, " "); got != "global deny /global" {
+		t.Errorf("unexpected steps %q", got)
+	}
+}
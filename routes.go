@@ -0,0 +1,168 @@
+package go_rookie
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+/**
+ * Route
+ * @Description: 一条已注册的路由，注册方法返回该对象，可通过Name设置路由名称
+ */
+type Route struct {
+	method      string
+	path        string // 完整路由
+	name        string // 路由名称，用于反向生成url
+	handler     HandlerFunc
+	middlewares []HandlerFunc // 路由级别中间件
+	handlers    []HandlerFunc // 组装好的完整处理链
+	group       *routerGroup
+}
+
+// compile 组装路由的完整处理链
+func (r *Route) compile() {
+	handlers := make([]HandlerFunc, 0, len(r.middlewares)+1)
+	handlers = append(handlers, r.middlewares...)
+	r.handlers = r.group.combineHandlers(append(handlers, r.handler)...)
+}
+
+/**
+ * RouteInfo
+ * @Description: 路由信息，用于启动时打印路由、生成文档等
+ */
+type RouteInfo struct {
+	Method      string // 请求方式
+	Host        string // 绑定的域名规则，默认路由器为空
+	Path        string // 完整路由
+	Name        string // 路由名称
+	Handler     string // 处理函数名称
+	Middlewares int    // 生效的中间件数量（全局+分组+路由）
+}
+
+/**
+ * Name
+ * @Author：Jack-Z
+ * @Description: 设置路由名称，名称在整个Engine中唯一
+ * @receiver r
+ * @param name
+ * @return *Route
+ */
+func (r *Route) Name(name string) *Route {
+	engine := r.group.router.engine
+	if exist, ok := engine.namedRoutes[name]; ok && exist != r {
+		panic(fmt.Errorf("duplicate route name '%s': %s %s and %s %s", name, exist.method, exist.path, r.method, r.path))
+	}
+	if r.name != "" {
+		delete(engine.namedRoutes, r.name)
+	}
+	r.name = name
+	engine.namedRoutes[name] = r
+	return r
+}
+
+/**
+ * Routes
+ * @Author：Jack-Z
+ * @Description: 返回所有路由的信息，默认路由器在前，各路由器内按注册顺序
+ * @receiver e
+ * @return []RouteInfo
+ */
+func (e *Engine) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(e.routes))
+	for _, router := range e.allRouters() {
+		for _, route := range router.routes {
+			routes = append(routes, route.info())
+		}
+	}
+	return routes
+}
+
+func (r *Route) info() RouteInfo {
+	return RouteInfo{
+		Method:      r.method,
+		Host:        r.group.router.host,
+		Path:        r.path,
+		Name:        r.name,
+		Handler:     nameOfFunction(r.handler),
+		Middlewares: len(r.handlers) - 1,
+	}
+}
+
+/**
+ * URL
+ * @Author：Jack-Z
+ * @Description: 根据路由名称反向生成url，如路由“/user/:id”的名称为“user.show”时，
+ * URL("user.show", "id", 12) 返回“/user/12”，未用到的参数拼接为query参数
+ * @receiver e
+ * @param name 路由名称
+ * @param params 参数，按 key, value, key, value... 的形式传入
+ * @return string
+ * @return error
+ */
+func (e *Engine) URL(name string, params ...any) (string, error) {
+	route, ok := e.namedRoutes[name]
+	if !ok {
+		return "", fmt.Errorf("route name [%s] not found", name)
+	}
+	if len(params)%2 != 0 {
+		return "", errors.New("params must be key/value pairs")
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("param key %v is not a string", params[i])
+		}
+		values[key] = fmt.Sprint(params[i+1])
+	}
+
+	used := make(map[string]bool)
+	segments := strings.Split(route.path, "/")
+	for i, segment := range segments {
+		key, ok := paramKey(segment)
+		if !ok {
+			continue
+		}
+		value, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("missing param [%s] for route %s", key, route.path)
+		}
+		if _, constraint := parseParam(segment); constraint != "" {
+			if matcher, err := compileConstraint(constraint); err == nil && !matcher(value) {
+				return "", fmt.Errorf("param [%s] value '%s' does not match constraint '%s'", key, value, constraint)
+			}
+		}
+		used[key] = true
+		if segment == "**" {
+			// 通配参数可以包含多段路径，逐段转义
+			parts := strings.Split(value, "/")
+			for j := range parts {
+				parts[j] = url.PathEscape(parts[j])
+			}
+			segments[i] = strings.Join(parts, "/")
+		} else {
+			segments[i] = url.PathEscape(value)
+		}
+	}
+
+	path := strings.Join(segments, "/")
+	query := url.Values{}
+	for key, value := range values {
+		if !used[key] {
+			query.Set(key, value)
+		}
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return path, nil
+}
+
+// nameOfFunction 获取函数名称
+func nameOfFunction(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/Jack-ZL/go_rookie/grace"
 	"github.com/Jack-ZL/go_rookie/register"
 	"golang.org/x/time/rate"
 	"google.golang.org/protobuf/proto"
@@ -17,6 +18,7 @@ import (
 	"log"
 	"net"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -236,12 +238,22 @@ type GrTcpServer struct {
 	RegisterType   string //注册类型：nacos或etcd
 	RegisterOption register.Option
 	RegisterCli    register.GrRegister
-	LimiterTimeOut time.Duration // 限流超时时间
-	Limiter        *rate.Limiter // 限流器
+	LimiterTimeOut time.Duration  // 限流超时时间
+	Limiter        *rate.Limiter  // 限流器
+	conns          sync.WaitGroup // 处理中的连接
 }
 
+/**
+ * NewTcpServer
+ * @Author：Jack-Z
+ * @Description: 创建tcp服务，由热重启或systemd启动时使用继承的监听器
+ * @param host
+ * @param port
+ * @return *GrTcpServer
+ * @return error
+ */
 func NewTcpServer(host string, port int) (*GrTcpServer, error) {
-	listen, err := net.Listen("tcp", fmt.Sprintf("%s:%d", host, port))
+	listen, err := grace.Listen("tcp", fmt.Sprintf("%s:%d", host, port))
 	if err != nil {
 		return nil, err
 	}
@@ -361,38 +373,73 @@ func (c GrTcpConn) Send(rsp *GrRpcResponse) error {
 /**
  * Stop
  * @Author：Jack-Z
- * @Description: 终止服务
+ * @Description: 终止服务，停止接收新连接，Run在处理中的连接完成后返回
  * @receiver s
  */
 func (s *GrTcpServer) Stop() {
 	err := s.listen.Close()
-	if err != nil {
+	if err != nil && !errors.Is(err, net.ErrClosed) {
 		log.Println(err)
 	}
 }
 
+/**
+ * Restart
+ * @Author：Jack-Z
+ * @Description: 热重启：启动新进程并把监听器传过去，当前进程停止接收新连接，
+ * 处理中的连接完成后Run返回。同一进程中的Engine也会随之优雅关闭
+ * @receiver s
+ * @return error
+ */
+func (s *GrTcpServer) Restart() error {
+	if err := grace.Restart(); err != nil {
+		return err
+	}
+	s.Stop()
+	return nil
+}
+
 /**
  * Run
  * @Author：Jack-Z
- * @Description: 运行服务
+ * @Description: 运行服务，Stop或热重启后等待处理中的连接完成再返回
  * @receiver s
  */
 func (s *GrTcpServer) Run() {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-grace.Restarted():
+			s.Stop()
+		case <-stop:
+		}
+	}()
 	for {
 		conn, err := s.listen.Accept()
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				s.conns.Wait()
+				return
+			}
 			log.Println(err)
 			continue
 		}
 		msConn := &GrTcpConn{conn: conn, rspChan: make(chan *GrRpcResponse, 1)}
 		// 1. 一直接收数据 解码工作 请求业务获取结果 发送到rspChan
 		// 2. 获得结果 编码 发送数据
+		s.conns.Add(1)
 		go s.readHandle(msConn)
-		go s.writeHandle(msConn)
+		go func() {
+			defer s.conns.Done()
+			s.writeHandle(msConn)
+		}()
 	}
 }
 
 func (s *GrTcpServer) readHandle(conn *GrTcpConn) {
+	// 处理结束后关闭rspChan，没有响应时writeHandle也能退出
+	defer close(conn.rspChan)
 	defer func() {
 		if err := recover(); err != nil {
 			log.Println("readHandle recover ", err)
@@ -527,8 +574,11 @@ func (s *GrTcpServer) readHandle(conn *GrTcpConn) {
  */
 func (s *GrTcpServer) writeHandle(conn *GrTcpConn) {
 	select {
-	case rsp := <-conn.rspChan:
+	case rsp, ok := <-conn.rspChan:
 		defer conn.conn.Close()
+		if !ok {
+			return
+		}
 		// 发送数据
 		err := conn.Send(rsp)
 		if err != nil {
@@ -0,0 +1,229 @@
+/**
+ * Package securecookie
+ * @Description: cookie值的签名（HMAC-SHA256）和加密（AES-256-GCM），支持密钥轮换，过期时间保存在值中
+ */
+package securecookie
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxValueLen = 4096 // 浏览器对单个cookie的大小限制
+
+var (
+	ErrNoKeys  = errors.New("securecookie: no keys configured")
+	ErrInvalid = errors.New("securecookie: invalid or tampered value")
+	ErrExpired = errors.New("securecookie: value expired")
+	ErrTooLong = errors.New("securecookie: encoded value exceeds 4096 bytes")
+)
+
+var encoding = base64.RawURLEncoding
+
+type keyPair struct {
+	sign    []byte
+	encrypt cipher.AEAD
+}
+
+/**
+ * Codec
+ * @Description: 第一个密钥用于签名和加密，其余密钥只用于验证和解密，
+ * 轮换时把新密钥放在最前面，旧密钥保留到使用它的cookie全部过期
+ */
+type Codec struct {
+	keys []keyPair
+	now  func() time.Time
+}
+
+/**
+ * New
+ * @Author：Jack-Z
+ * @Description: 根据密钥创建Codec，每个密钥分别派生签名密钥和加密密钥，建议至少32字节的随机数据
+ * @param secrets 按新旧排序的密钥
+ * @return *Codec
+ */
+func New(secrets ...[]byte) *Codec {
+	c := &Codec{now: time.Now}
+	for _, secret := range secrets {
+		block, _ := aes.NewCipher(derive(secret, "encrypt"))
+		aead, _ := cipher.NewGCM(block)
+		c.keys = append(c.keys, keyPair{sign: derive(secret, "sign"), encrypt: aead})
+	}
+	return c
+}
+
+// derive 用HMAC从密钥派生指定用途的32字节子密钥
+func derive(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("go_rookie securecookie " + purpose))
+	return mac.Sum(nil)
+}
+
+/**
+ * Sign
+ * @Author：Jack-Z
+ * @Description: 生成签名后的值，格式为“过期时间.值.签名”，值没有加密，客户端可以读取。
+ * 签名包含cookie名称，不能把一个cookie的值用于另一个cookie
+ * @receiver c
+ * @param name cookie名称
+ * @param value
+ * @param expires 零值表示不过期
+ * @return string
+ * @return error
+ */
+func (c *Codec) Sign(name string, value []byte, expires time.Time) (string, error) {
+	if len(c.keys) == 0 {
+		return "", ErrNoKeys
+	}
+	payload := strconv.FormatInt(unix(expires), 10) + "." + encoding.EncodeToString(value)
+	encoded := payload + "." + encoding.EncodeToString(c.mac(c.keys[0].sign, name, payload))
+	if len(encoded) > maxValueLen {
+		return "", ErrTooLong
+	}
+	return encoded, nil
+}
+
+/**
+ * Verify
+ * @Author：Jack-Z
+ * @Description: 依次使用各密钥验证签名，并检查过期时间
+ * @receiver c
+ * @param name
+ * @param encoded Sign生成的值
+ * @return []byte
+ * @return error 签名不正确时返回ErrInvalid，过期时返回ErrExpired
+ */
+func (c *Codec) Verify(name, encoded string) ([]byte, error) {
+	if len(c.keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	i := strings.LastIndexByte(encoded, '.')
+	if i < 0 {
+		return nil, ErrInvalid
+	}
+	payload := encoded[:i]
+	signature, err := encoding.DecodeString(encoded[i+1:])
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	valid := false
+	for _, key := range c.keys {
+		if hmac.Equal(signature, c.mac(key.sign, name, payload)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, ErrInvalid
+	}
+	expiresPart, valuePart, ok := strings.Cut(payload, ".")
+	if !ok {
+		return nil, ErrInvalid
+	}
+	expires, err := strconv.ParseInt(expiresPart, 10, 64)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	if c.expired(expires) {
+		return nil, ErrExpired
+	}
+	value, err := encoding.DecodeString(valuePart)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	return value, nil
+}
+
+func (c *Codec) mac(key []byte, name, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'|'})
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+/**
+ * Encrypt
+ * @Author：Jack-Z
+ * @Description: 用AES-GCM加密值和过期时间，cookie名称作为附加数据参与认证
+ * @receiver c
+ * @param name
+ * @param value
+ * @param expires 零值表示不过期
+ * @return string
+ * @return error
+ */
+func (c *Codec) Encrypt(name string, value []byte, expires time.Time) (string, error) {
+	if len(c.keys) == 0 {
+		return "", ErrNoKeys
+	}
+	aead := c.keys[0].encrypt
+	plaintext := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(plaintext, uint64(unix(expires)))
+	plaintext = append(plaintext, value...)
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	encoded := encoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, []byte(name)))
+	if len(encoded) > maxValueLen {
+		return "", ErrTooLong
+	}
+	return encoded, nil
+}
+
+/**
+ * Decrypt
+ * @Author：Jack-Z
+ * @Description: 依次使用各密钥解密，并检查过期时间
+ * @receiver c
+ * @param name
+ * @param encoded Encrypt生成的值
+ * @return []byte
+ * @return error 无法解密时返回ErrInvalid，过期时返回ErrExpired
+ */
+func (c *Codec) Decrypt(name, encoded string) ([]byte, error) {
+	if len(c.keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	data, err := encoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	for _, key := range c.keys {
+		aead := key.encrypt
+		if len(data) < aead.NonceSize()+aead.Overhead()+8 {
+			return nil, ErrInvalid
+		}
+		plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+		if err != nil {
+			continue
+		}
+		if c.expired(int64(binary.BigEndian.Uint64(plaintext))) {
+			return nil, ErrExpired
+		}
+		return plaintext[8:], nil
+	}
+	return nil, ErrInvalid
+}
+
+func (c *Codec) expired(expires int64) bool {
+	return expires != 0 && c.now().Unix() >= expires
+}
+
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
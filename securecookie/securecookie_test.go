@@ -0,0 +1,53 @@
+package securecookie
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCodec(t *testing.T) {
+	oldKey, newKey := []byte("old-secret"), []byte("new-secret")
+	old := New(oldKey)
+	rotated := New(newKey, oldKey)
+	expires := time.Now().Add(time.Hour)
+
+	for _, method := range []string{"sign", "encrypt"} {
+		encode, decode := old.Sign, rotated.Verify
+		if method == "encrypt" {
+			encode, decode = old.Encrypt, rotated.Decrypt
+		}
+		encoded, err := encode("cart", []byte("42"), expires)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 轮换后仍能读取旧密钥生成的值
+		if value, err := decode("cart", encoded); err != nil || string(value) != "42" {
+			t.Fatalf("%s: unexpected result %q %v", method, value, err)
+		}
+		if _, err := decode("locale", encoded); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("%s: value accepted for another cookie name: %v", method, err)
+		}
+		tampered := []byte(encoded)
+		tampered[len(tampered)/2] ^= 1
+		if _, err := decode("cart", string(tampered)); err == nil {
+			t.Fatalf("%s: tampered value accepted", method)
+		}
+		if _, err := New(newKey).Verify("cart", encoded); method == "sign" && !errors.Is(err, ErrInvalid) {
+			t.Fatalf("%s: removed key still accepted: %v", method, err)
+		}
+
+		rotated.now = func() time.Time { return expires.Add(time.Second) }
+		if _, err := decode("cart", encoded); !errors.Is(err, ErrExpired) {
+			t.Fatalf("%s: unexpected error %v", method, err)
+		}
+		rotated.now = time.Now
+	}
+
+	if _, err := New().Sign("cart", nil, time.Time{}); !errors.Is(err, ErrNoKeys) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := old.Encrypt("cart", make([]byte, 4096), time.Time{}); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,367 @@
+package go_rookie
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/Jack-ZL/go_rookie/grace"
+	"github.com/Jack-ZL/go_rookie/register"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const defaultShutdownTimeout = 10 * time.Second // 默认的优雅关闭超时时间
+
+/**
+ * ServerOption
+ * @Description: http.Server的配置项，Run、RunTLS、RunListener、RunUnix启动的服务都会使用，
+ * 超时时间为0时表示不限制，建议在公网环境中设置以防御slowloris之类的慢速攻击
+ */
+type ServerOption struct {
+	ReadTimeout       time.Duration // 读取整个请求（含请求体）的超时时间
+	ReadHeaderTimeout time.Duration // 读取请求头的超时时间，为0时使用ReadTimeout
+	WriteTimeout      time.Duration // 写响应的超时时间
+	IdleTimeout       time.Duration // keep-alive连接的空闲超时时间，为0时使用ReadTimeout
+	MaxHeaderBytes    int           // 请求头的最大字节数，为0时使用http.DefaultMaxHeaderBytes
+	H2C               bool          // 是否支持不加密的HTTP/2（h2c），用于内网或sidecar之间通信
+	TLSConfig         *tls.Config   // https的配置，如最低TLS版本、加密套件
+	UnixSocketMode    os.FileMode   // Unix socket文件的权限，为0时不修改
+	ErrorLog          *log.Logger   // 连接错误等内部日志，为nil时使用标准库log
+}
+
+/**
+ * Run
+ * @Author：Jack-Z
+ * @Description: 启动并建监听一个端口，收到SIGINT/SIGTERM后优雅关闭，
+ * 正常关闭时返回nil。由Restart或systemd启动时直接使用继承的监听器
+ * @receiver e
+ * @param addr
+ * @return error
+ */
+func (e *Engine) Run(addr string) error {
+	if addr == "" {
+		addr = ":http"
+	}
+	listener, err := grace.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return e.RunListener(listener)
+}
+
+/**
+ * RunTLS
+ * @Author：Jack-Z
+ * @Description: 支持https安全
+ * @receiver e
+ * @param addr
+ * @param certFile
+ * @param keyFile
+ * @return error
+ */
+func (e *Engine) RunTLS(addr, certFile, keyFile string) error {
+	if addr == "" {
+		addr = ":https"
+	}
+	listener, err := grace.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return e.RunTLSListener(listener, certFile, keyFile)
+}
+
+/**
+ * RunListener
+ * @Author：Jack-Z
+ * @Description: 在已打开的监听器上启动服务，如由父进程或systemd传入的监听器。
+ * 同一个Engine可以多次调用Run系列方法同时监听多个地址（如http和https），
+ * 收到退出信号或调用Shutdown时全部关闭
+ * @receiver e
+ * @param listener
+ * @return error
+ */
+func (e *Engine) RunListener(listener net.Listener) error {
+	if err := e.startRegister(); err != nil {
+		listener.Close()
+		return err
+	}
+	srv := e.newServer(listener.Addr().String())
+	return e.serve(srv, func() error {
+		return srv.Serve(listener)
+	})
+}
+
+/**
+ * RunTLSListener
+ * @Author：Jack-Z
+ * @Description: 在已打开的监听器上启动https服务
+ * @receiver e
+ * @param listener
+ * @param certFile
+ * @param keyFile
+ * @return error
+ */
+func (e *Engine) RunTLSListener(listener net.Listener, certFile, keyFile string) error {
+	if err := e.startRegister(); err != nil {
+		listener.Close()
+		return err
+	}
+	srv := e.newServer(listener.Addr().String())
+	return e.serve(srv, func() error {
+		return srv.ServeTLS(listener, certFile, keyFile)
+	})
+}
+
+/**
+ * RunUnix
+ * @Author：Jack-Z
+ * @Description: 在Unix domain socket上启动服务，如本机的sidecar代理转发的场景。
+ * 上次运行遗留的socket文件会被删除，服务关闭时socket文件随之删除（热重启时保留）
+ * @receiver e
+ * @param file socket文件路径
+ * @return error
+ */
+func (e *Engine) RunUnix(file string) error {
+	listener, err := grace.Listen("unix", file)
+	if err != nil {
+		return err
+	}
+	if e.ServerOption.UnixSocketMode != 0 {
+		if err := os.Chmod(file, e.ServerOption.UnixSocketMode); err != nil {
+			listener.Close()
+			return err
+		}
+	}
+	return e.RunListener(listener)
+}
+
+/**
+ * Restart
+ * @Author：Jack-Z
+ * @Description: 热重启：启动当前程序的新进程并把监听器传过去，新进程接管新连接，
+ * 当前进程不注销服务，等待处理中的请求完成后关闭，Run随之返回。
+ * 设置HotRestart后收到SIGHUP时自动调用
+ * @receiver e
+ * @return error
+ */
+func (e *Engine) Restart() error {
+	if err := grace.Restart(); err != nil {
+		return err
+	}
+	return e.Shutdown(context.Background())
+}
+
+/**
+ * newServer
+ * @Author：Jack-Z
+ * @Description: 按ServerOption创建http.Server
+ * @receiver e
+ * @param addr
+ * @return *http.Server
+ */
+func (e *Engine) newServer(addr string) *http.Server {
+	opt := e.ServerOption
+	var handler http.Handler = e
+	if opt.H2C {
+		handler = h2c.NewHandler(e, &http2.Server{IdleTimeout: opt.IdleTimeout})
+	}
+	var tlsConfig *tls.Config
+	if opt.TLSConfig != nil {
+		tlsConfig = opt.TLSConfig.Clone()
+	}
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadTimeout:       opt.ReadTimeout,
+		ReadHeaderTimeout: opt.ReadHeaderTimeout,
+		WriteTimeout:      opt.WriteTimeout,
+		IdleTimeout:       opt.IdleTimeout,
+		MaxHeaderBytes:    opt.MaxHeaderBytes,
+		ErrorLog:          opt.ErrorLog,
+	}
+}
+
+/**
+ * OnShutdown
+ * @Author：Jack-Z
+ * @Description: 注册关闭钩子，在请求处理完成、服务注销之后按注册顺序执行
+ * @receiver e
+ * @param hooks
+ */
+func (e *Engine) OnShutdown(hooks ...func()) {
+	e.shutdownHooks = append(e.shutdownHooks, hooks...)
+}
+
+/**
+ * Shutdown
+ * @Author：Jack-Z
+ * @Description: 优雅关闭：从注册中心注销服务，停止接收新连接并等待处理中的请求，
+ * 最后执行关闭钩子。多次调用只执行一次，Run会随之返回
+ * @receiver e
+ * @param ctx 在其基础上再限制ShutdownTimeout的超时时间
+ * @return error
+ */
+func (e *Engine) Shutdown(ctx context.Context) error {
+	e.shutdownOnce.Do(func() {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		timeout := e.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = defaultShutdownTimeout
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		// 先注销服务，避免网关继续把请求转发过来；热重启时新进程使用同一地址，不注销
+		e.setShutdownErr(e.deregister(!grace.IsRestarting()))
+
+		e.serverMu.Lock()
+		e.shuttingDown = true
+		servers := e.servers
+		e.serverMu.Unlock()
+		for _, srv := range servers {
+			if err := srv.Shutdown(ctx); err != nil {
+				e.setShutdownErr(err)
+				// 超时后强制关闭剩余的连接（包括被接管的长连接）
+				if ctx.Err() != nil {
+					srv.Close()
+				}
+			}
+		}
+
+		for _, hook := range e.shutdownHooks {
+			hook()
+		}
+		close(e.done)
+	})
+	<-e.done
+	return e.shutdownErr
+}
+
+/**
+ * serve
+ * @Author：Jack-Z
+ * @Description: 运行服务并等待退出信号
+ * @receiver e
+ * @param srv
+ * @param serveFunc 启动监听的函数
+ * @return error
+ */
+func (e *Engine) serve(srv *http.Server, serveFunc func() error) error {
+	e.serverMu.Lock()
+	if e.shuttingDown {
+		// 关闭流程已经开始，服务启动后立即返回http.ErrServerClosed
+		srv.Close()
+	} else {
+		e.servers = append(e.servers, srv)
+	}
+	e.serverMu.Unlock()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	hup := make(chan os.Signal, 1)
+	if e.HotRestart {
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- serveFunc()
+	}()
+
+	for {
+		select {
+		case err := <-errChan:
+			if !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			// 由Shutdown关闭，等待关闭流程结束
+			<-e.done
+			return e.shutdownErr
+		case <-quit:
+			return e.Shutdown(context.Background())
+		case <-hup:
+			// 重启失败时继续提供服务
+			if err := e.Restart(); err != nil {
+				log.Println("restart failed:", err)
+				continue
+			}
+			return e.shutdownErr
+		case <-grace.Restarted():
+			// 其他服务（如rpc）触发了热重启
+			return e.Shutdown(context.Background())
+		}
+	}
+}
+
+/**
+ * startRegister
+ * @Author：Jack-Z
+ * @Description: 创建注册中心客户端，设置了AutoRegister和服务名称时注册服务，只执行一次
+ * @receiver e
+ * @return error
+ */
+func (e *Engine) startRegister() error {
+	e.registerOnce.Do(func() {
+		var cli register.GrRegister
+		if e.RegisterType == "nacos" {
+			cli = &register.GrNacosRegister{}
+		}
+		if e.RegisterType == "etcd" {
+			cli = &register.GrEtcdRegister{}
+		}
+		if cli != nil {
+			if err := cli.CreateCli(e.RegisterOption); err != nil {
+				e.registerErr = err
+				return
+			}
+			e.RegisterCli = cli
+		}
+		if e.RegisterCli != nil && e.RegisterOption.AutoRegister && e.RegisterOption.ServiceName != "" {
+			e.registerErr = e.RegisterCli.RegisterService(e.RegisterOption.ServiceName, e.RegisterOption.Host, e.RegisterOption.Port)
+			e.registered = e.registerErr == nil
+		}
+	})
+	return e.registerErr
+}
+
+/**
+ * deregister
+ * @Author：Jack-Z
+ * @Description: 注销由startRegister注册的服务（客户端实现了register.GrDeregister时）并关闭注册中心客户端，
+ * 手动或由其他进程注册的服务不注销
+ * @receiver e
+ * @param unregister 是否注销服务，为false时只关闭客户端
+ * @return error
+ */
+func (e *Engine) deregister(unregister bool) error {
+	if e.RegisterCli == nil {
+		return nil
+	}
+	var err error
+	if d, ok := e.RegisterCli.(register.GrDeregister); ok && unregister && e.registered {
+		err = d.DeregisterService(e.RegisterOption.ServiceName, e.RegisterOption.Host, e.RegisterOption.Port)
+	}
+	if closeErr := e.RegisterCli.Close(); closeErr != nil {
+		log.Println(closeErr)
+	}
+	return err
+}
+
+// setShutdownErr 记录关闭过程中的第一个错误
+func (e *Engine) setShutdownErr(err error) {
+	if err != nil && e.shutdownErr == nil {
+		e.shutdownErr = err
+	}
+}
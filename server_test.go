@@ -0,0 +1,59 @@
+package go_rookie
+
+import (
+	"github.com/Jack-ZL/go_rookie/register"
+	"testing"
+)
+
+// fakeRegister 记录注册和注销的次数
+type fakeRegister struct {
+	registered   int
+	deregistered int
+	closed       bool
+}
+
+func (f *fakeRegister) CreateCli(option register.Option) error { return nil }
+
+func (f *fakeRegister) RegisterService(serviceName string, host string, port int) error {
+	f.registered++
+	return nil
+}
+
+func (f *fakeRegister) DeregisterService(serviceName string, host string, port int) error {
+	f.deregistered++
+	return nil
+}
+
+func (f *fakeRegister) GetValue(serviceName string) (string, error) { return "", nil }
+
+func (f *fakeRegister) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestRegisterLifecycle(t *testing.T) {
+	tests := []struct {
+		name         string
+		autoRegister bool
+		want         int
+	}{
+		// 手动或由其他进程注册的服务，关闭时不注销
+		{"manual", false, 0},
+		{"auto", true, 1},
+	}
+	for _, tt := range tests {
+		cli := &fakeRegister{}
+		engine := New()
+		engine.RegisterCli = cli
+		engine.RegisterOption = register.Option{ServiceName: "user", AutoRegister: tt.autoRegister}
+		if err := engine.startRegister(); err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.deregister(true); err != nil {
+			t.Fatal(err)
+		}
+		if cli.registered != tt.want || cli.deregistered != tt.want || !cli.closed {
+			t.Errorf("%s: unexpected register calls %+v", tt.name, cli)
+		}
+	}
+}
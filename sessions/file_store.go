@@ -0,0 +1,127 @@
+package sessions
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const filePrefix = "sess_"
+
+/**
+ * FileStore
+ * @Description: 文件存储，每个session一个文件，文件开头8字节为过期时间（unix纳秒），之后为session数据
+ */
+type FileStore struct {
+	dir string
+}
+
+/**
+ * NewFileStore
+ * @Author：Jack-Z
+ * @Description: 创建文件存储，目录不存在时创建（权限0700）
+ * @param dir
+ * @return *FileStore
+ * @return error
+ */
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(id string) (string, error) {
+	if !validID(id) {
+		return "", errors.New("sessions: invalid session id")
+	}
+	return filepath.Join(s.dir, filePrefix+id), nil
+}
+
+func (s *FileStore) Load(id string) ([]byte, bool, error) {
+	name, err := s.path(id)
+	if err != nil {
+		return nil, false, nil
+	}
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(data) < 8 || fileExpired(data) {
+		return nil, false, nil
+	}
+	return data[8:], true, nil
+}
+
+// Save 先写临时文件再重命名，并发读取时不会读到不完整的数据
+func (s *FileStore) Save(id string, data []byte, expiresAt time.Time) error {
+	name, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.dir, ".tmp_")
+	if err != nil {
+		return err
+	}
+	var header [8]byte
+	binary.BigEndian.PutUint64(header[:], uint64(expiresAt.UnixNano()))
+	_, err = f.Write(append(header[:], data...))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func (s *FileStore) Delete(id string) error {
+	name, err := s.path(id)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// GC 删除过期的session文件
+func (s *FileStore) GC() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), filePrefix) {
+			continue
+		}
+		name := filepath.Join(s.dir, entry.Name())
+		f, err := os.Open(name)
+		if err != nil {
+			continue
+		}
+		header := make([]byte, 8)
+		_, err = io.ReadFull(f, header)
+		f.Close()
+		if err != nil || fileExpired(header) {
+			os.Remove(name)
+		}
+	}
+	return nil
+}
+
+func fileExpired(data []byte) bool {
+	expiresAt := int64(binary.BigEndian.Uint64(data[:8]))
+	return time.Now().UnixNano() >= expiresAt
+}
@@ -0,0 +1,425 @@
+/**
+ * Package sessions
+ * @Description: 服务端session，通过cookie中的session id关联，支持空闲超时、绝对超时、闪存消息和session id轮换
+ */
+package sessions
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"github.com/Jack-ZL/go_rookie"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	contextKey    = "go_rookie/sessions"
+	flashPrefix   = "_flash:"
+	idBytes       = 32
+	defaultCookie = "go_rookie_session"
+)
+
+var ErrNotFound = errors.New("sessions: session middleware not installed")
+
+func init() {
+	// 闪存消息以[]any保存
+	gob.Register([]any{})
+}
+
+/**
+ * Config
+ * @Description: session配置，零值字段使用默认值
+ */
+type Config struct {
+	Store           Store         // session的存储，必须设置
+	CookieName      string        // 保存session id的cookie名称，默认go_rookie_session
+	Path            string        // cookie的Path，默认“/”
+	Domain          string        // cookie的Domain
+	Secure          bool          // 是否只通过https发送cookie
+	SameSite        http.SameSite // 默认http.SameSiteLaxMode
+	Persistent      bool          // 为true时cookie在session过期时失效，否则在浏览器关闭时失效
+	IdleTimeout     time.Duration // 空闲超时，超过该时间没有访问时session失效，默认30分钟
+	AbsoluteTimeout time.Duration // 绝对超时，从创建（或Regenerate）起超过该时间session失效，默认24小时
+}
+
+/**
+ * Manager
+ * @Description: 按Config加载和保存session，通过Middleware接入处理链
+ */
+type Manager struct {
+	config Config
+}
+
+/**
+ * New
+ * @Author：Jack-Z
+ * @Description: 创建session管理器
+ * @param config
+ * @return *Manager
+ */
+func New(config Config) *Manager {
+	if config.Store == nil {
+		panic(errors.New("sessions: store is required"))
+	}
+	if config.CookieName == "" {
+		config.CookieName = defaultCookie
+	}
+	if config.Path == "" {
+		config.Path = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = 30 * time.Minute
+	}
+	if config.AbsoluteTimeout <= 0 {
+		config.AbsoluteTimeout = 24 * time.Hour
+	}
+	return &Manager{config: config}
+}
+
+/**
+ * Middleware
+ * @Author：Jack-Z
+ * @Description: 根据cookie加载session（不存在或已过期时创建新session），
+ * 在写出响应头之前自动保存，新建且没有写入数据的session不会保存
+ * @receiver m
+ * @param next
+ * @return go_rookie.HandlerFunc
+ */
+func (m *Manager) Middleware(next go_rookie.HandlerFunc) go_rookie.HandlerFunc {
+	return func(ctx *go_rookie.Context) {
+		s := m.load(ctx.R)
+		s.w = ctx.W
+		ctx.Set(contextKey, s)
+		ctx.W.Before(func(w go_rookie.ResponseWriter) {
+			if err := s.autoSave(); err != nil && ctx.Logger != nil {
+				ctx.Logger.Error("sessions: save failed: " + err.Error())
+			}
+		})
+		next(ctx)
+	}
+}
+
+/**
+ * Get
+ * @Author：Jack-Z
+ * @Description: 获取当前请求的session，需要先注册Middleware，否则panic
+ * @param ctx
+ * @return *Session
+ */
+func Get(ctx *go_rookie.Context) *Session {
+	s, err := Lookup(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Lookup 获取当前请求的session，没有注册Middleware时返回ErrNotFound
+func Lookup(ctx *go_rookie.Context) (*Session, error) {
+	value, ok := ctx.Get(contextKey)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return value.(*Session), nil
+}
+
+// load 读取cookie对应的session，失效的session被删除并替换为新session
+func (m *Manager) load(r *http.Request) *Session {
+	now := time.Now()
+	if cookie, err := r.Cookie(m.config.CookieName); err == nil && validID(cookie.Value) {
+		data, ok, err := m.config.Store.Load(cookie.Value)
+		if err == nil && ok {
+			var rec record
+			if gob.NewDecoder(bytes.NewReader(data)).Decode(&rec) == nil && !m.expired(rec, now) {
+				if rec.Values == nil {
+					rec.Values = make(map[string]any)
+				}
+				return &Session{manager: m, id: cookie.Value, rec: rec}
+			}
+			_ = m.config.Store.Delete(cookie.Value)
+		}
+	}
+	return m.newSession(now)
+}
+
+func (m *Manager) newSession(now time.Time) *Session {
+	return &Session{
+		manager: m,
+		id:      newID(),
+		isNew:   true,
+		rec:     record{Values: make(map[string]any), CreatedAt: now, LastAccess: now},
+	}
+}
+
+func (m *Manager) expired(rec record, now time.Time) bool {
+	return !now.Before(m.expiresAt(rec))
+}
+
+// expiresAt 空闲超时和绝对超时中较早的一个
+func (m *Manager) expiresAt(rec record) time.Time {
+	idle := rec.LastAccess.Add(m.config.IdleTimeout)
+	absolute := rec.CreatedAt.Add(m.config.AbsoluteTimeout)
+	if idle.Before(absolute) {
+		return idle
+	}
+	return absolute
+}
+
+// record 保存到Store中的数据
+type record struct {
+	Values     map[string]any
+	CreatedAt  time.Time
+	LastAccess time.Time
+}
+
+/**
+ * Session
+ * @Description: 一个请求中的session，保存的值使用gob编码，自定义类型需要先gob.Register
+ */
+type Session struct {
+	mu        sync.Mutex
+	manager   *Manager
+	w         http.ResponseWriter
+	id        string
+	oldID     string // Regenerate前的id，保存时从Store中删除
+	rec       record
+	isNew     bool
+	modified  bool
+	destroyed bool
+	saved     bool // 本次请求是否已经保存过
+}
+
+// ID session id
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// IsNew 是否为本次请求新建的session
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isNew
+}
+
+// CreatedAt session的创建时间，Regenerate时重置
+func (s *Session) CreatedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rec.CreatedAt
+}
+
+func (s *Session) Get(key string) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rec.Values[key]
+}
+
+func (s *Session) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rec.Values[key] = value
+	s.modified = true
+}
+
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rec.Values[key]; ok {
+		delete(s.rec.Values, key)
+		s.modified = true
+	}
+}
+
+// Clear 删除所有值，session本身仍然有效
+func (s *Session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rec.Values = make(map[string]any)
+	s.modified = true
+}
+
+/**
+ * Flash
+ * @Author：Jack-Z
+ * @Description: 添加闪存消息，读取一次后即删除，常用于重定向后显示提示
+ * @receiver s
+ * @param value
+ * @param key 消息分类，默认为“_flash”
+ */
+func (s *Session) Flash(value any, key ...string) {
+	name := flashKey(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes, _ := s.rec.Values[name].([]any)
+	s.rec.Values[name] = append(flashes, value)
+	s.modified = true
+}
+
+// Flashes 读取并删除闪存消息
+func (s *Session) Flashes(key ...string) []any {
+	name := flashKey(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes, ok := s.rec.Values[name].([]any)
+	if ok {
+		delete(s.rec.Values, name)
+		s.modified = true
+	}
+	return flashes
+}
+
+func flashKey(key []string) string {
+	if len(key) > 0 {
+		return flashPrefix + key[0]
+	}
+	return flashPrefix
+}
+
+/**
+ * Regenerate
+ * @Author：Jack-Z
+ * @Description: 更换session id并保留数据，登录、提升权限后调用以防止session固定攻击；
+ * 旧id在保存时从Store中删除，绝对超时重新计算
+ * @receiver s
+ */
+func (s *Session) Regenerate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.isNew && s.oldID == "" {
+		s.oldID = s.id
+	}
+	s.id = newID()
+	s.rec.CreatedAt = time.Now()
+	s.modified = true
+}
+
+// Destroy 删除session，如退出登录，保存时从Store中删除并让cookie失效
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.destroyed = true
+}
+
+/**
+ * Save
+ * @Author：Jack-Z
+ * @Description: 立即保存session并设置cookie，需要在写出响应体之前调用；
+ * 通常不需要手动调用，Middleware会在写出响应头之前自动保存
+ * @receiver s
+ * @return error
+ */
+func (s *Session) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save()
+}
+
+// autoSave 有修改，或已有session在本次请求中还没有保存（更新最后访问时间）时保存
+func (s *Session) autoSave() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.modified || s.destroyed || (!s.isNew && !s.saved) {
+		return s.save()
+	}
+	return nil
+}
+
+func (s *Session) save() error {
+	store := s.manager.config.Store
+	if s.destroyed {
+		s.setCookie("", -1)
+		if s.oldID != "" {
+			_ = store.Delete(s.oldID)
+		}
+		if s.isNew {
+			return nil
+		}
+		return store.Delete(s.id)
+	}
+	if s.isNew && !s.modified {
+		return nil
+	}
+
+	s.rec.LastAccess = time.Now()
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s.rec); err != nil {
+		return err
+	}
+	expiresAt := s.manager.expiresAt(s.rec)
+	if err := store.Save(s.id, buf.Bytes(), expiresAt); err != nil {
+		return err
+	}
+	if s.oldID != "" {
+		if err := store.Delete(s.oldID); err != nil {
+			return err
+		}
+		s.oldID = ""
+	}
+	maxAge := 0
+	if s.manager.config.Persistent {
+		maxAge = int(time.Until(expiresAt) / time.Second)
+	}
+	s.setCookie(s.id, maxAge)
+	s.isNew, s.modified, s.saved = false, false, true
+	return nil
+}
+
+// setCookie 设置session cookie，替换本次响应中已经设置过的同名cookie
+func (s *Session) setCookie(value string, maxAge int) {
+	if s.w == nil {
+		return
+	}
+	config := s.manager.config
+	header := s.w.Header()
+	prefix := config.CookieName + "="
+	cookies := header.Values("Set-Cookie")
+	header.Del("Set-Cookie")
+	for _, cookie := range cookies {
+		if !strings.HasPrefix(cookie, prefix) {
+			header.Add("Set-Cookie", cookie)
+		}
+	}
+	http.SetCookie(s.w, &http.Cookie{
+		Name:     config.CookieName,
+		Value:    value,
+		Path:     config.Path,
+		Domain:   config.Domain,
+		MaxAge:   maxAge,
+		Secure:   config.Secure,
+		HttpOnly: true,
+		SameSite: config.SameSite,
+	})
+}
+
+// newID 生成256位随机的session id
+func newID() string {
+	b := make([]byte, idBytes)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// validID 是否为newID生成的格式，避免把任意字符串传给Store（如文件路径）
+func validID(id string) bool {
+	if len(id) != base64.RawURLEncoding.EncodedLen(idBytes) {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
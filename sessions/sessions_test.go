@@ -0,0 +1,106 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/Jack-ZL/go_rookie"
+	"github.com/Jack-ZL/go_rookie/gorookietest"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newEngine(store Store, idle time.Duration) *go_rookie.Engine {
+	engine := go_rookie.New()
+	group := engine.Group("")
+	group.Use(New(Config{Store: store, IdleTimeout: idle}).Middleware)
+	group.Get("/anonymous", func(ctx *go_rookie.Context) {
+		ctx.String(http.StatusOK, "ok")
+	})
+	group.Get("/login", func(ctx *go_rookie.Context) {
+		s := Get(ctx)
+		s.Regenerate()
+		s.Set("user", "jack")
+		s.Flash("welcome")
+		ctx.String(http.StatusOK, "ok")
+	})
+	group.Get("/me", func(ctx *go_rookie.Context) {
+		s := Get(ctx)
+		ctx.String(http.StatusOK, fmt.Sprintf("%v %v", s.Get("user"), s.Flashes()))
+	})
+	group.Get("/logout", func(ctx *go_rookie.Context) {
+		Get(ctx).Destroy()
+		ctx.String(http.StatusOK, "ok")
+	})
+	return engine
+}
+
+func sessionCookie(t *testing.T, resp *gorookietest.Response) *http.Cookie {
+	t.Helper()
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == defaultCookie {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	memoryStore := NewMemoryStore(time.Minute)
+	defer memoryStore.Close()
+
+	for _, store := range []Store{memoryStore, fileStore} {
+		engine := newEngine(store, time.Hour)
+
+		// 没有写入数据的新session不保存
+		if c := sessionCookie(t, gorookietest.Get(t, engine, "/anonymous").Do()); c != nil {
+			t.Fatalf("%T: unexpected cookie for anonymous request", store)
+		}
+
+		// 登录前的session id（攻击者预先植入的）在登录后失效
+		fixed := &http.Cookie{Name: defaultCookie, Value: newID()}
+		now := time.Now()
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(record{Values: map[string]any{"user": "guest"}, CreatedAt: now, LastAccess: now}); err != nil {
+			t.Fatal(err)
+		}
+		store.Save(fixed.Value, buf.Bytes(), now.Add(time.Hour))
+		gorookietest.Get(t, engine, "/me").Cookie(fixed).Do().AssertBody("guest []")
+		login := sessionCookie(t, gorookietest.Get(t, engine, "/login").Cookie(fixed).Do())
+		if login == nil || login.Value == fixed.Value || !login.HttpOnly {
+			t.Fatalf("%T: unexpected login cookie %v", store, login)
+		}
+		if _, ok, _ := store.Load(fixed.Value); ok {
+			t.Fatalf("%T: fixated session id still valid", store)
+		}
+
+		gorookietest.Get(t, engine, "/me").Cookie(login).Do().AssertBody("jack [welcome]")
+		// 闪存消息只能读取一次
+		gorookietest.Get(t, engine, "/me").Cookie(login).Do().AssertBody("jack []")
+
+		logout := sessionCookie(t, gorookietest.Get(t, engine, "/logout").Cookie(login).Do())
+		if logout == nil || logout.MaxAge >= 0 {
+			t.Fatalf("%T: unexpected logout cookie %v", store, logout)
+		}
+		gorookietest.Get(t, engine, "/me").Cookie(login).Do().AssertBody("<nil> []")
+	}
+}
+
+func TestSessionIdleTimeout(t *testing.T) {
+	store := NewMemoryStore(0)
+	engine := newEngine(store, 50*time.Millisecond)
+	login := sessionCookie(t, gorookietest.Get(t, engine, "/login").Do())
+	gorookietest.Get(t, engine, "/me").Cookie(login).Do().AssertBody("jack [welcome]")
+	time.Sleep(80 * time.Millisecond)
+	gorookietest.Get(t, engine, "/me").Cookie(login).Do().AssertBody("<nil> []")
+
+	store.GC()
+	if store.Len() != 0 {
+		t.Fatalf("expired sessions not collected: %d", store.Len())
+	}
+}
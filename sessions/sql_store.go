@@ -0,0 +1,81 @@
+package sessions
+
+import (
+	"encoding/base64"
+	"github.com/Jack-ZL/go_rookie/orm"
+	"time"
+)
+
+/**
+ * SQLStore
+ * @Description: 基于orm.GrDb的数据库存储，表结构（MySQL）：
+ *
+ *	CREATE TABLE sessions (
+ *		id         VARCHAR(64) NOT NULL PRIMARY KEY,
+ *		data       MEDIUMTEXT  NOT NULL,
+ *		expires_at BIGINT      NOT NULL,
+ *		KEY idx_expires_at (expires_at)
+ *	)
+ */
+type SQLStore struct {
+	db    *orm.GrDb
+	table string
+}
+
+type sessionRow struct {
+	Id        string `json:"id"`
+	Data      string `json:"data"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+/**
+ * NewSQLStore
+ * @Author：Jack-Z
+ * @Description: 创建数据库存储
+ * @param db
+ * @param table 表名，为空时使用sessions
+ * @return *SQLStore
+ */
+func NewSQLStore(db *orm.GrDb, table string) *SQLStore {
+	if table == "" {
+		table = "sessions"
+	}
+	return &SQLStore{db: db, table: table}
+}
+
+func (s *SQLStore) session() *orm.GrSession {
+	return s.db.New(&sessionRow{}).Table(s.table)
+}
+
+func (s *SQLStore) Load(id string) ([]byte, bool, error) {
+	row := &sessionRow{}
+	err := s.session().QueryRow("SELECT id, data, expires_at FROM "+s.table+" WHERE id = ? AND expires_at > ?", row, id, time.Now().Unix())
+	if err != nil {
+		return nil, false, err
+	}
+	if row.Id == "" {
+		return nil, false, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(row.Data)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (s *SQLStore) Save(id string, data []byte, expiresAt time.Time) error {
+	_, err := s.session().QueryExec("REPLACE INTO "+s.table+" (id, data, expires_at) VALUES (?, ?, ?)",
+		id, base64.StdEncoding.EncodeToString(data), expiresAt.Unix())
+	return err
+}
+
+func (s *SQLStore) Delete(id string) error {
+	_, err := s.session().QueryExec("DELETE FROM "+s.table+" WHERE id = ?", id)
+	return err
+}
+
+// GC 删除过期的session
+func (s *SQLStore) GC() error {
+	_, err := s.session().QueryExec("DELETE FROM "+s.table+" WHERE expires_at <= ?", time.Now().Unix())
+	return err
+}
@@ -0,0 +1,116 @@
+package sessions
+
+import (
+	"sync"
+	"time"
+)
+
+/**
+ * Store
+ * @Description: session的存储接口，可以基于Redis、数据库等实现，如Redis中
+ * Save对应SET key data PXAT expiresAt，Load对应GET，Delete对应DEL
+ */
+type Store interface {
+	Load(id string) ([]byte, bool, error)                   // 读取session数据，不存在或已过期时返回false
+	Save(id string, data []byte, expiresAt time.Time) error // 保存session数据，expiresAt之后可以删除
+	Delete(id string) error                                 // 删除session，不存在时不返回错误
+}
+
+type memoryEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+/**
+ * MemoryStore
+ * @Description: 进程内存储，重启后session丢失，只适合单实例部署
+ */
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]memoryEntry
+	stop     chan struct{}
+	once     sync.Once
+}
+
+/**
+ * NewMemoryStore
+ * @Author：Jack-Z
+ * @Description: 创建内存存储，每隔gcInterval清理一次过期的session
+ * @param gcInterval 为0时不自动清理，可以手动调用GC
+ * @return *MemoryStore
+ */
+func NewMemoryStore(gcInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		sessions: make(map[string]memoryEntry),
+		stop:     make(chan struct{}),
+	}
+	if gcInterval > 0 {
+		go s.gcLoop(gcInterval)
+	}
+	return s
+}
+
+func (s *MemoryStore) Load(id string) ([]byte, bool, error) {
+	s.mu.RLock()
+	entry, ok := s.sessions[id]
+	s.mu.RUnlock()
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return nil, false, nil
+	}
+	return entry.data, true, nil
+}
+
+func (s *MemoryStore) Save(id string, data []byte, expiresAt time.Time) error {
+	s.mu.Lock()
+	s.sessions[id] = memoryEntry{data: append([]byte(nil), data...), expiresAt: expiresAt}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// Len 当前保存的session数（含未清理的过期session）
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.sessions)
+}
+
+// GC 清理过期的session
+func (s *MemoryStore) GC() error {
+	now := time.Now()
+	s.mu.Lock()
+	for id, entry := range s.sessions {
+		if !now.Before(entry.expiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Close 停止自动清理
+func (s *MemoryStore) Close() error {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	return nil
+}
+
+func (s *MemoryStore) gcLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = s.GC()
+		case <-s.stop:
+			return
+		}
+	}
+}
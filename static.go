@@ -0,0 +1,229 @@
+package go_rookie
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/**
+ * StaticConfig
+ * @Description: 静态文件服务配置
+ */
+type StaticConfig struct {
+	Root         http.FileSystem // 文件系统，如http.Dir("./public")，embed.FS可以通过http.FS转换
+	Index        []string        // 访问目录时查找的默认文件，为空时使用index.html
+	Browse       bool            // 目录下没有默认文件时，是否列出目录内容
+	CacheControl string          // Cache-Control响应头，如“public, max-age=3600”，为空时不设置
+}
+
+/**
+ * Static
+ * @Author：Jack-Z
+ * @Description: 挂载本地目录，如 Static("/assets", "./public")
+ * @receiver r
+ * @param relativePath
+ * @param root
+ * @return *Route
+ */
+func (r *routerGroup) Static(relativePath, root string) *Route {
+	return r.StaticFS(relativePath, http.Dir(root))
+}
+
+/**
+ * StaticFS
+ * @Author：Jack-Z
+ * @Description: 挂载文件系统，embed.FS可以通过http.FS转换后传入
+ * @receiver r
+ * @param relativePath
+ * @param fs
+ * @return *Route
+ */
+func (r *routerGroup) StaticFS(relativePath string, fs http.FileSystem) *Route {
+	return r.StaticWithConfig(relativePath, StaticConfig{Root: fs})
+}
+
+/**
+ * StaticWithConfig
+ * @Author：Jack-Z
+ * @Description: 按配置挂载文件系统，支持默认文件、目录列表、ETag/Last-Modified、Range请求和缓存控制
+ * @receiver r
+ * @param relativePath
+ * @param conf
+ * @return *Route
+ */
+func (r *routerGroup) StaticWithConfig(relativePath string, conf StaticConfig) *Route {
+	if strings.ContainsAny(relativePath, ":*{") {
+		panic(fmt.Errorf("static path '%s' can not contain params", relativePath))
+	}
+	if len(conf.Index) == 0 {
+		conf.Index = []string{"index.html"}
+	}
+	handler := &staticHandler{conf: conf}
+	return r.Get(path.Join(relativePath, "/**"), func(ctx *Context) {
+		handler.serve(ctx, ctx.Param("**"))
+	})
+}
+
+/**
+ * StaticFile
+ * @Author：Jack-Z
+ * @Description: 挂载单个文件，如 StaticFile("/favicon.ico", "./public/favicon.ico")
+ * @receiver r
+ * @param relativePath
+ * @param filePath
+ * @return *Route
+ */
+func (r *routerGroup) StaticFile(relativePath, filePath string) *Route {
+	if strings.ContainsAny(relativePath, ":*{") {
+		panic(fmt.Errorf("static path '%s' can not contain params", relativePath))
+	}
+	handler := &staticHandler{conf: StaticConfig{Root: http.Dir(filepath.Dir(filePath))}}
+	name := filepath.Base(filePath)
+	return r.Get(relativePath, func(ctx *Context) {
+		handler.serve(ctx, name)
+	})
+}
+
+func (e *Engine) Static(relativePath, root string) *Route {
+	return e.Group("").Static(relativePath, root)
+}
+
+func (e *Engine) StaticFS(relativePath string, fs http.FileSystem) *Route {
+	return e.Group("").StaticFS(relativePath, fs)
+}
+
+func (e *Engine) StaticWithConfig(relativePath string, conf StaticConfig) *Route {
+	return e.Group("").StaticWithConfig(relativePath, conf)
+}
+
+func (e *Engine) StaticFile(relativePath, filePath string) *Route {
+	return e.Group("").StaticFile(relativePath, filePath)
+}
+
+type staticHandler struct {
+	conf StaticConfig
+}
+
+/**
+ * serve
+ * @Author：Jack-Z
+ * @Description: 处理静态文件请求
+ * @receiver h
+ * @param ctx
+ * @param name 相对挂载目录的路径
+ */
+func (h *staticHandler) serve(ctx *Context, name string) {
+	if containsDotDot(name) {
+		ctx.String(http.StatusBadRequest, "invalid path")
+		return
+	}
+	name = path.Clean("/" + name)
+	f, err := h.conf.Root.Open(name)
+	if err != nil {
+		ctx.engine.noRoute(ctx)
+		return
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		ctx.engine.noRoute(ctx)
+		return
+	}
+
+	if stat.IsDir() {
+		// 目录统一以“/”结尾，保证页面中的相对路径正确
+		if !strings.HasSuffix(ctx.R.URL.Path, "/") {
+			ctx.Redirect(http.StatusMovedPermanently, path.Base(ctx.R.URL.Path)+"/")
+			return
+		}
+		for _, index := range h.conf.Index {
+			indexFile, err := h.conf.Root.Open(path.Join(name, index))
+			if err != nil {
+				continue
+			}
+			defer indexFile.Close()
+			indexStat, err := indexFile.Stat()
+			if err != nil || indexStat.IsDir() {
+				continue
+			}
+			h.serveFile(ctx, indexFile, indexStat)
+			return
+		}
+		if !h.conf.Browse {
+			ctx.engine.noRoute(ctx)
+			return
+		}
+		h.dirList(ctx, f)
+		return
+	}
+	h.serveFile(ctx, f, stat)
+}
+
+/**
+ * serveFile
+ * @Author：Jack-Z
+ * @Description: 输出文件内容，ETag、条件请求和Range请求的处理同Context.File
+ * @receiver h
+ * @param ctx
+ * @param f
+ * @param stat
+ */
+func (h *staticHandler) serveFile(ctx *Context, f http.File, stat os.FileInfo) {
+	if h.conf.CacheControl != "" {
+		ctx.W.Header().Set("Cache-Control", h.conf.CacheControl)
+	}
+	ctx.serveFile(f, stat)
+}
+
+/**
+ * dirList
+ * @Author：Jack-Z
+ * @Description: 列出目录内容
+ * @receiver h
+ * @param ctx
+ * @param f
+ */
+func (h *staticHandler) dirList(ctx *Context, f http.File) {
+	files, err := f.Readdir(-1)
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, "error reading directory")
+		return
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name() < files[j].Name()
+	})
+	var sb strings.Builder
+	sb.WriteString("<pre>\n")
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() {
+			name += "/"
+		}
+		u := url.URL{Path: name}
+		fmt.Fprintf(&sb, "<a href=\"%s\">%s</a>\n", u.String(), html.EscapeString(name))
+	}
+	sb.WriteString("</pre>\n")
+	ctx.HTML(http.StatusOK, sb.String())
+}
+
+// containsDotDot 路径中是否包含“..”段，用于拒绝目录穿越
+func containsDotDot(name string) bool {
+	if !strings.Contains(name, "..") {
+		return false
+	}
+	for _, segment := range strings.FieldsFunc(name, func(r rune) bool {
+		return r == '/' || r == '\\'
+	}) {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
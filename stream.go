@@ -0,0 +1,120 @@
+package go_rookie
+
+import (
+	"github.com/Jack-ZL/go_rookie/render"
+	"io"
+	"net/http"
+	"time"
+)
+
+/**
+ * SSEvent
+ * @Author：Jack-Z
+ * @Description: 输出一条Server-Sent Events事件并立即flush
+ * @receiver c
+ * @param name 事件名称
+ * @param data string、[]byte原样输出，其他类型编码为json
+ * @return error
+ */
+func (c *Context) SSEvent(name string, data any) error {
+	return c.WriteSSE(render.SSEvent{Event: name, Data: data})
+}
+
+/**
+ * WriteSSE
+ * @Author：Jack-Z
+ * @Description: 输出一条完整的事件（可设置id、retry、注释）并立即flush
+ * @receiver c
+ * @param event
+ * @return error
+ */
+func (c *Context) WriteSSE(event render.SSEvent) error {
+	err := c.Render(http.StatusOK, &event)
+	c.W.Flush()
+	return err
+}
+
+/**
+ * LastEventID
+ * @Author：Jack-Z
+ * @Description: 客户端重连时带回的最后一条事件id，用于断点续传；
+ * 优先读取Last-Event-ID请求头，其次是query参数lastEventId（部分polyfill使用）
+ * @receiver c
+ * @return string
+ */
+func (c *Context) LastEventID() string {
+	if id := c.R.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return c.R.URL.Query().Get("lastEventId")
+}
+
+/**
+ * Stream
+ * @Author：Jack-Z
+ * @Description: 流式输出，循环调用step并在每次调用后flush，
+ * step返回false或客户端断开连接时结束
+ * @receiver c
+ * @param step 向w写入一段数据，返回是否继续
+ * @return bool 是否因为客户端断开连接而结束
+ */
+func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	done := c.R.Context().Done()
+	for {
+		select {
+		case <-done:
+			return true
+		default:
+			keepOpen := step(c.W)
+			c.W.Flush()
+			if !keepOpen {
+				return false
+			}
+		}
+	}
+}
+
+/**
+ * SSEStream
+ * @Author：Jack-Z
+ * @Description: 把events中的事件依次输出给客户端，空闲超过heartbeat时发送注释行作为心跳，
+ * 防止代理因连接空闲而断开。events关闭或客户端断开连接时结束
+ * @receiver c
+ * @param events
+ * @param heartbeat 心跳间隔，为0时不发送心跳
+ * @return bool 是否因为客户端断开连接而结束
+ */
+func (c *Context) SSEStream(events <-chan render.SSEvent, heartbeat time.Duration) bool {
+	done := c.R.Context().Done()
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	if heartbeat > 0 {
+		ticker = time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	// 先写出响应头，客户端可以立即确认连接建立
+	(&render.SSEvent{}).WriteContentType(c.W)
+	c.W.WriteHeader(http.StatusOK)
+	c.W.Flush()
+	for {
+		select {
+		case <-done:
+			return true
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			if err := c.WriteSSE(event); err != nil {
+				return true
+			}
+			if ticker != nil {
+				ticker.Reset(heartbeat)
+			}
+		case <-tick:
+			if err := c.WriteSSE(render.SSEvent{Comment: "heartbeat"}); err != nil {
+				return true
+			}
+		}
+	}
+}
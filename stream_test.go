@@ -0,0 +1,121 @@
+package go_rookie_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/Jack-ZL/go_rookie"
+	"github.com/Jack-ZL/go_rookie/gorookietest"
+	"github.com/Jack-ZL/go_rookie/render"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSSEvent(t *testing.T) {
+	engine := go_rookie.New()
+	engine.Group("").Get("/events", func(ctx *go_rookie.Context) {
+		ctx.WriteSSE(render.SSEvent{Id: ctx.LastEventID() + "1", Event: "update", Data: "line1\nline2", Retry: 3000})
+		ctx.SSEvent("user", map[string]int{"id": 7})
+	})
+
+	tests := []struct {
+		header string
+		query  string
+		id     string
+	}{
+		{"", "", "1"},
+		{"41", "", "411"},
+		{"", "9", "91"},
+	}
+	for _, tt := range tests {
+		req := gorookietest.Get(t, engine, "/events")
+		if tt.header != "" {
+			req.Header("Last-Event-ID", tt.header)
+		}
+		if tt.query != "" {
+			req.Query("lastEventId", tt.query)
+		}
+		resp := req.Do().AssertStatus(http.StatusOK).AssertHeader("Content-Type", "text/event-stream")
+		want := "id: " + tt.id + "\nevent: update\nretry: 3000\ndata: line1\ndata: line2\n\nevent: user\ndata: {\"id\":7}\n\n"
+		resp.AssertBody(want)
+		if !resp.Flushed {
+			t.Error("expected flushed response")
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	// step返回false时结束
+	ctx, w := gorookietest.NewContext(nil, nil)
+	i := 0
+	disconnected := ctx.Stream(func(w io.Writer) bool {
+		i++
+		fmt.Fprintf(w, "chunk %d\n", i)
+		return i < 3
+	})
+	if disconnected || w.Body.String() != "chunk 1\nchunk 2\nchunk 3\n" || !w.Flushed {
+		t.Fatalf("unexpected stream %v %q", disconnected, w.Body.String())
+	}
+
+	// 客户端断开连接时结束
+	reqCtx, cancel := context.WithCancel(context.Background())
+	ctx, w = gorookietest.NewContext(nil, httptest.NewRequest("GET", "/", nil).WithContext(reqCtx))
+	i = 0
+	disconnected = ctx.Stream(func(w io.Writer) bool {
+		i++
+		if i == 2 {
+			cancel()
+		}
+		fmt.Fprintf(w, "chunk %d\n", i)
+		return true
+	})
+	if !disconnected || i != 2 {
+		t.Fatalf("unexpected stream %v %d", disconnected, i)
+	}
+}
+
+// heartbeatRecorder 第一次写出心跳时关闭heartbeat
+type heartbeatRecorder struct {
+	*httptest.ResponseRecorder
+	once      sync.Once
+	heartbeat chan struct{}
+}
+
+func (r *heartbeatRecorder) Write(b []byte) (int, error) {
+	return r.WriteString(string(b))
+}
+
+func (r *heartbeatRecorder) WriteString(s string) (int, error) {
+	n, err := r.ResponseRecorder.WriteString(s)
+	if strings.Contains(s, ": heartbeat") {
+		r.once.Do(func() { close(r.heartbeat) })
+	}
+	return n, err
+}
+
+func TestSSEStreamHeartbeat(t *testing.T) {
+	w := &heartbeatRecorder{ResponseRecorder: httptest.NewRecorder(), heartbeat: make(chan struct{})}
+	ctx := go_rookie.New().NewContext(w, httptest.NewRequest("GET", "/", nil))
+	events := make(chan render.SSEvent)
+	go func() {
+		// 等到写出心跳之后再发送事件
+		select {
+		case <-w.heartbeat:
+		case <-time.After(5 * time.Second):
+			t.Error("no heartbeat sent")
+		}
+		events <- render.SSEvent{Data: "hello"}
+		close(events)
+	}()
+	if ctx.SSEStream(events, 10*time.Millisecond) {
+		t.Fatal("unexpected disconnect")
+	}
+	body := w.Body.String()
+	if !strings.HasPrefix(body, ": heartbeat\n\n") || !strings.HasSuffix(body, "data: hello\n\n") {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
@@ -1,80 +1,391 @@
 package go_rookie
 
-import "strings"
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
 
+type nodeType uint8
+
+const (
+	staticNode   nodeType = iota // 静态节点
+	paramNode                    // 参数节点，“:name”、“{name}”、“{name:约束}”或“*”，匹配单个路径段
+	catchAllNode                 // 通配节点，“**”，匹配剩余的全部路径
+)
+
+/**
+ * treeNode
+ * @Description: 压缩前缀树（radix tree）节点，整个路由器共用一棵树。
+ * 匹配优先级：静态节点 > 带约束的参数节点（按注册顺序） > 无约束的参数节点 > 通配节点
+ */
 type treeNode struct {
-	name       string
-	children   []*treeNode
-	routerName string
-	isEnd      bool // 是否是尾节点标识
+	name       string            // 静态节点为压缩后的公共片段，参数/通配节点为路由段原文
+	nType      nodeType          // 节点类型
+	key        string            // 参数名，“:id”对应“id”，“*”和“**”对应自身
+	constraint string            // 参数约束，如“int”、“[0-9]+”，为空时不限制
+	matcher    func(string) bool // 参数约束的校验函数
+	indices    string            // 静态子节点的首字符，与children一一对应
+	children   []*treeNode       // 静态子节点
+	params     []*treeNode       // 参数子节点，带约束的在前，无约束的最多一个且在最后
+	anyChild   *treeNode         // 通配子节点
+	fullPath   string            // 从根节点到当前节点的完整路由
+	isEnd      bool              // 是否是尾节点标识
+	pattern    string            // 尾节点上注册路由时使用的原文
+	routes     map[string]*Route // 尾节点上按请求方式注册的路由，可以来自不同分组
+	methods    []string          // 按注册顺序的请求方式，用于Allow响应头
 }
 
-func (t *treeNode) Put(path string) {
-	root := t
-	strs := strings.Split(path, "/")
-	for index, name := range strs {
-		if index == 0 {
-			continue
+// addRoute 在尾节点上添加一个请求方式的路由
+func (t *treeNode) addRoute(pattern, method string, route *Route) {
+	if t.routes == nil {
+		t.routes = make(map[string]*Route)
+	}
+	t.pattern = pattern
+	t.routes[method] = route
+	t.methods = append(t.methods, method)
+}
+
+/**
+ * Put
+ * @Author：Jack-Z
+ * @Description: 添加路由，返回路由对应的尾节点；存在歧义的路由返回错误
+ * @receiver t 根节点，只作为容器，自身的name不参与匹配
+ * @param path 完整路由，如“/user/:id”
+ * @return *treeNode
+ * @return error
+ */
+func (t *treeNode) Put(path string) (*treeNode, error) {
+	if path == "" || path[0] != '/' {
+		return nil, fmt.Errorf("route '%s' must begin with '/'", path)
+	}
+	n := t
+	rest := path
+	for {
+		start, end := findWildcard(rest)
+		if start < 0 {
+			n = n.putStatic(rest, path[:len(path)-len(rest)])
+			break
 		}
-		children := t.children
-		isMatch := false
-		for _, node := range children {
-			if node.name == name {
-				isMatch = true
-				t = node
-				break
+		n = n.putStatic(rest[:start], path[:len(path)-len(rest)])
+		segment := rest[start:end]
+		fullPath := path[:len(path)-len(rest)+end]
+		if segment == "**" {
+			if end != len(rest) {
+				return nil, fmt.Errorf("catch-all '**' is only allowed at the end of route '%s'", path)
 			}
-		}
-		if !isMatch {
-			isEnd := false
-			if index == len(strs)-1 {
-				isEnd = true
+			if n.anyChild == nil {
+				n.anyChild = &treeNode{name: segment, nType: catchAllNode, key: segment, fullPath: fullPath}
 			}
-			node := &treeNode{
-				name:     name,
-				children: make([]*treeNode, 0),
-				isEnd:    isEnd,
+			n = n.anyChild
+		} else {
+			child, err := n.putParam(segment, fullPath)
+			if err != nil {
+				return nil, fmt.Errorf("%w in '%s'", err, path)
 			}
-			children = append(children, node)
-			t.children = children
-			t = node
+			n = child
 		}
+		rest = rest[end:]
 	}
-	t = root
+	n.isEnd = true
+	return n, nil
 }
 
-func (t *treeNode) Get(path string) *treeNode {
-	strs := strings.Split(path, "/")
-	routerName := ""
-	for index, name := range strs {
-		if index == 0 {
+/**
+ * putParam
+ * @Author：Jack-Z
+ * @Description: 插入参数子节点，约束相同的参数段复用已有节点；
+ * 约束相同但参数名不同、或约束无法解析时返回错误
+ * @receiver t
+ * @param segment 参数段原文
+ * @param fullPath 到该参数段为止的完整路由
+ * @return *treeNode
+ * @return error
+ */
+func (t *treeNode) putParam(segment, fullPath string) (*treeNode, error) {
+	key, constraint := parseParam(segment)
+	for _, child := range t.params {
+		if child.constraint != constraint {
 			continue
 		}
-		children := t.children
-		isMatch := false
-
-		for _, node := range children {
-			if node.name == name || node.name == "*" || strings.Contains(node.name, ":") {
-				isMatch = true
-				routerName += "/" + node.name
-				node.routerName = routerName
-				t = node
-				if index == len(strs)-1 {
-					return node
-				}
-				break
-			}
+		if child.key != key {
+			return nil, fmt.Errorf("route conflict: '%s' conflicts with existing '%s' in '%s'",
+				segment, child.name, child.fullPath)
+		}
+		return child, nil
+	}
+	child := &treeNode{name: segment, nType: paramNode, key: key, constraint: constraint, fullPath: fullPath}
+	if constraint == "" {
+		t.params = append(t.params, child)
+		return child, nil
+	}
+	matcher, err := compileConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+	child.matcher = matcher
+	// 带约束的节点插入到无约束节点之前
+	i := len(t.params)
+	if i > 0 && t.params[i-1].constraint == "" {
+		i--
+	}
+	t.params = append(t.params, nil)
+	copy(t.params[i+1:], t.params[i:])
+	t.params[i] = child
+	return child, nil
+}
+
+/**
+ * putStatic
+ * @Author：Jack-Z
+ * @Description: 在静态子节点中插入一段静态路由，必要时拆分已有节点
+ * @receiver t
+ * @param s 静态路由片段
+ * @param prefix 片段之前的完整路由
+ * @return *treeNode 片段结束处的节点
+ */
+func (t *treeNode) putStatic(s string, prefix string) *treeNode {
+	n := t
+	for s != "" {
+		i := strings.IndexByte(n.indices, s[0])
+		if i < 0 {
+			child := &treeNode{name: s, nType: staticNode, fullPath: prefix + s}
+			n.indices += s[:1]
+			n.children = append(n.children, child)
+			return child
+		}
+		child := n.children[i]
+		l := longestCommonPrefix(s, child.name)
+		if l < len(child.name) {
+			child.split(l)
 		}
+		prefix += s[:l]
+		s = s[l:]
+		n = child
+	}
+	return n
+}
+
+// split 在位置i处拆分静态节点，当前节点保留公共前缀，其余内容下沉为唯一的子节点
+func (t *treeNode) split(i int) {
+	child := *t
+	child.name = t.name[i:]
+	*t = treeNode{
+		name:     t.name[:i],
+		nType:    staticNode,
+		indices:  child.name[:1],
+		children: []*treeNode{&child},
+		fullPath: t.fullPath[:len(t.fullPath)-len(child.name)],
+	}
+}
+
+/**
+ * Get
+ * @Author：Jack-Z
+ * @Description: 路由匹配，同时捕获路径参数（:name、{name:约束}、* 匹配单段，** 匹配剩余路径）
+ * @receiver t
+ * @param path
+ * @return *treeNode 匹配到的尾节点，未匹配返回nil
+ * @return map[string]string
+ */
+func (t *treeNode) Get(path string) (*treeNode, map[string]string) {
+	var params map[string]string
+	node := t.match(path, &params)
+	return node, params
+}
 
-		if !isMatch {
-			for _, node := range children {
-				if node.name == "**" {
-					routerName += "/" + node.name
-					node.routerName = routerName
+/**
+ * match
+ * @Author：Jack-Z
+ * @Description: 从当前节点的子节点开始匹配剩余路径，失败时回溯到优先级更低的节点
+ * 参数只在匹配成功的分支上写入
+ * @receiver t
+ * @param path 尚未匹配的路径
+ * @param params
+ * @return *treeNode
+ */
+func (t *treeNode) match(path string, params *map[string]string) *treeNode {
+	if path == "" {
+		if t.isEnd {
+			return t
+		}
+		if t.anyChild != nil && t.anyChild.isEnd {
+			setParam(params, t.anyChild.key, "")
+			return t.anyChild
+		}
+		return nil
+	}
+	// 静态节点
+	if i := strings.IndexByte(t.indices, path[0]); i >= 0 {
+		child := t.children[i]
+		if strings.HasPrefix(path, child.name) {
+			if node := child.match(path[len(child.name):], params); node != nil {
+				return node
+			}
+		}
+	}
+	// 参数节点，不满足约束的跳过
+	if len(t.params) > 0 {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end > 0 {
+			segment := path[:end]
+			for _, child := range t.params {
+				if child.matcher != nil && !child.matcher(segment) {
+					continue
+				}
+				if node := child.match(path[end:], params); node != nil {
+					setParam(params, child.key, segment)
 					return node
 				}
 			}
 		}
 	}
+	// 通配节点
+	if t.anyChild != nil && t.anyChild.isEnd {
+		setParam(params, t.anyChild.key, path)
+		return t.anyChild
+	}
 	return nil
 }
+
+func setParam(params *map[string]string, key, value string) {
+	if *params == nil {
+		*params = make(map[string]string)
+	}
+	(*params)[key] = value
+}
+
+/**
+ * findWildcard
+ * @Author：Jack-Z
+ * @Description: 查找路由中第一个通配段（:name、{name}、{name:约束}、*、**）
+ * @param path
+ * @return int 通配段起始位置，没有时返回-1
+ * @return int 通配段结束位置
+ */
+func findWildcard(path string) (int, int) {
+	for i := 0; i < len(path); i++ {
+		if path[i] != '/' {
+			continue
+		}
+		start := i + 1
+		end := strings.IndexByte(path[start:], '/')
+		if end < 0 {
+			end = len(path)
+		} else {
+			end += start
+		}
+		if isWildcard(path[start:end]) {
+			return start, end
+		}
+		i = end - 1
+	}
+	return -1, -1
+}
+
+func isWildcard(segment string) bool {
+	_, ok := paramKey(segment)
+	return ok
+}
+
+/**
+ * paramKey
+ * @Author：Jack-Z
+ * @Description: 获取路由段对应的参数名，“:id”、“{id}”和“{id:int}”对应“id”，“*”对应“*”
+ * @param name
+ * @return string
+ * @return bool 是否为参数段
+ */
+func paramKey(name string) (string, bool) {
+	if name == "*" || name == "**" {
+		return name, true
+	}
+	if len(name) > 1 && name[0] == ':' {
+		return name[1:], true
+	}
+	if len(name) > 2 && name[0] == '{' && name[len(name)-1] == '}' {
+		key, _ := parseParam(name)
+		return key, key != ""
+	}
+	return "", false
+}
+
+// parseParam 拆分参数段中的参数名和约束，“{id:[0-9]+}”对应“id”和“[0-9]+”
+func parseParam(segment string) (string, string) {
+	if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+		inner := segment[1 : len(segment)-1]
+		if i := strings.IndexByte(inner, ':'); i >= 0 {
+			return inner[:i], inner[i+1:]
+		}
+		return inner, ""
+	}
+	key, _ := paramKey(segment)
+	return key, ""
+}
+
+var (
+	paramTypesMu sync.RWMutex
+	paramTypes   = map[string]func(string) bool{
+		"int": func(s string) bool {
+			_, err := strconv.ParseInt(s, 10, 64)
+			return err == nil
+		},
+		"uint": func(s string) bool {
+			_, err := strconv.ParseUint(s, 10, 64)
+			return err == nil
+		},
+		"float": func(s string) bool {
+			_, err := strconv.ParseFloat(s, 64)
+			return err == nil
+		},
+		"bool": func(s string) bool {
+			_, err := strconv.ParseBool(s)
+			return err == nil
+		},
+		"uuid":  regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`).MatchString,
+		"alpha": regexp.MustCompile(`^[a-zA-Z]+$`).MatchString,
+		"alnum": regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString,
+	}
+)
+
+/**
+ * RegisterParamType
+ * @Author：Jack-Z
+ * @Description: 注册路由参数的内置类型，之后可以在路由中使用，如“/post/{slug:slug}”。
+ * 已内置int、uint、float、bool、uuid、alpha、alnum，需在注册路由之前调用
+ * @param name 类型名称
+ * @param fn 校验函数，参数为单个路径段
+ */
+func RegisterParamType(name string, fn func(string) bool) {
+	paramTypesMu.Lock()
+	defer paramTypesMu.Unlock()
+	paramTypes[name] = fn
+}
+
+// compileConstraint 解析参数约束，内置类型优先，否则按正则表达式整段匹配
+func compileConstraint(constraint string) (func(string) bool, error) {
+	paramTypesMu.RLock()
+	fn, ok := paramTypes[constraint]
+	paramTypesMu.RUnlock()
+	if ok {
+		return fn, nil
+	}
+	re, err := regexp.Compile("^(?:" + constraint + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid param constraint '%s': %w", constraint, err)
+	}
+	return re.MatchString, nil
+}
+
+func longestCommonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
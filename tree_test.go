@@ -2,28 +2,160 @@ package go_rookie
 
 import (
 	"fmt"
+	"net/http/httptest"
 	"testing"
 )
 
 func TestTreeNode(t *testing.T) {
-	root := &treeNode{
-		name:     "/",
-		children: make([]*treeNode, 0),
-	}
+	root := &treeNode{}
 	root.Put("/user/get/:id")
 	root.Put("/user/create/hello")
 	root.Put("/user/create/aaa")
 	root.Put("/order/get/aaa")
 
-	node := root.Get("/user/get/1")
+	node, _ := root.Get("/user/get/1")
 	fmt.Println(node)
 
-	node = root.Get("/user/create/hello")
+	node, _ = root.Get("/user/create/hello")
 	fmt.Println(node)
 
-	node = root.Get("/user/create/aaa")
+	node, _ = root.Get("/user/create/aaa")
 	fmt.Println(node)
 
-	node = root.Get("/order/get/aaa")
+	node, _ = root.Get("/order/get/aaa")
 	fmt.Println(node)
 }
+
+func TestTreeNodeParams(t *testing.T) {
+	root := &treeNode{}
+	root.Put("/user/get/:id")
+	root.Put("/file/*/info")
+	root.Put("/static/**")
+
+	node, params := root.Get("/user/get/12")
+	if node == nil || node.fullPath != "/user/get/:id" || params["id"] != "12" {
+		t.Fatalf("unexpected match: %v %v", node, params)
+	}
+	node, params = root.Get("/file/a.png/info")
+	if node == nil || params["*"] != "a.png" {
+		t.Fatalf("unexpected match: %v %v", node, params)
+	}
+	node, params = root.Get("/static/css/app.css")
+	if node == nil || params["**"] != "css/app.css" {
+		t.Fatalf("unexpected match: %v %v", node, params)
+	}
+}
+
+func TestTreeNodePriority(t *testing.T) {
+	routes := []string{"/user/:id", "/user/new", "/user/**", "/user/:id/profile", "/users", "/u"}
+	cases := map[string]string{
+		"/user/new":           "/user/new",
+		"/user/12":            "/user/:id",
+		"/user/12/profile":    "/user/:id/profile",
+		"/user/new/profile":   "/user/:id/profile",
+		"/user/12/orders/1":   "/user/**",
+		"/users":              "/users",
+		"/u":                  "/u",
+		"/user/":              "/user/**",
+		"/userx":              "",
+		"/order/get/whatever": "",
+	}
+	// 正序、倒序注册，匹配结果应一致
+	for _, reverse := range []bool{false, true} {
+		root := &treeNode{}
+		for i := range routes {
+			path := routes[i]
+			if reverse {
+				path = routes[len(routes)-1-i]
+			}
+			if _, err := root.Put(path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for path, want := range cases {
+			node, _ := root.Get(path)
+			got := ""
+			if node != nil {
+				got = node.fullPath
+			}
+			if got != want {
+				t.Errorf("reverse=%v %s: want %q, got %q", reverse, path, want, got)
+			}
+		}
+	}
+}
+
+func TestTreeNodeConflict(t *testing.T) {
+	root := &treeNode{}
+	if _, err := root.Put("/user/:id"); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/user/:name", "/user/*", "/files/**/x", "user"} {
+		if _, err := root.Put(path); err == nil {
+			t.Errorf("%s: expected conflict error", path)
+		}
+	}
+}
+
+func TestTreeNodeConstraint(t *testing.T) {
+	root := &treeNode{}
+	for _, path := range []string{"/order/{id:int}", "/order/{code:[A-Z]{3}}", "/order/:name", "/file/{name:.+\\.png}", "/item/{id:uuid}"} {
+		if _, err := root.Put(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := map[string]string{
+		"/order/12":   "/order/{id:int}",
+		"/order/ABC":  "/order/{code:[A-Z]{3}}",
+		"/order/abc":  "/order/:name",
+		"/file/a.png": "/file/{name:.+\\.png}",
+		"/file/a.jpg": "",
+		"/item/123e4567-e89b-12d3-a456-426614174000": "/item/{id:uuid}",
+		"/item/123": "",
+	}
+	for path, want := range cases {
+		node, _ := root.Get(path)
+		got := ""
+		if node != nil {
+			got = node.fullPath
+		}
+		if got != want {
+			t.Errorf("%s: want %q, got %q", path, want, got)
+		}
+	}
+	if _, params := root.Get("/order/12"); params["id"] != "12" {
+		t.Errorf("unexpected params: %v", params)
+	}
+	for _, path := range []string{"/order/{num:int}", "/x/{id:[0-9}"} {
+		if _, err := root.Put(path); err == nil {
+			t.Errorf("%s: expected error", path)
+		}
+	}
+}
+
+func TestRouteAcrossGroups(t *testing.T) {
+	engine := New()
+	engine.Group("/api").Get("/user", func(ctx *Context) {
+		ctx.String(200, "get")
+	})
+	engine.Group("").Post("/api/user", func(ctx *Context) {
+		ctx.String(200, "post")
+	})
+	for method, want := range map[string]string{"GET": "get", "POST": "post"} {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(method, "/api/user", nil))
+		if w.Code != 200 || w.Body.String() != want {
+			t.Errorf("%s: unexpected response %d %q", method, w.Code, w.Body.String())
+		}
+	}
+
+	// 同一路由用不同的写法注册时报冲突，不覆盖已有的路由
+	group := engine.Group("/user")
+	group.Get("/:id", func(ctx *Context) {})
+	defer func() {
+		if recover() == nil {
+			t.Error("expected route conflict")
+		}
+	}()
+	group.Post("/{id}", func(ctx *Context) {})
+}
@@ -0,0 +1,405 @@
+package go_rookie
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultMaxFieldSize = 1 << 20 // 普通表单字段默认最大1M
+	defaultMaxFields    = 1000    // 普通表单字段默认最多1000个
+	sniffLen            = 512     // http.DetectContentType最多使用前512字节
+	maxFilenameLen      = 255
+)
+
+var (
+	ErrUploadTooLarge       = errors.New("upload too large")
+	ErrTooManyFiles         = errors.New("too many files")
+	ErrTooManyFields        = errors.New("too many fields")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+/**
+ * MalformedUploadError
+ * @Description: 请求体不是合法的multipart表单，Upload响应400，Err为解析时的原始错误
+ */
+type MalformedUploadError struct {
+	Err error
+}
+
+func (e *MalformedUploadError) Error() string {
+	return "malformed multipart request: " + e.Err.Error()
+}
+
+func (e *MalformedUploadError) Unwrap() error {
+	return e.Err
+}
+
+/**
+ * UploadConfig
+ * @Description: 流式上传的限制，各数值为0时不限制（MaxFieldSize为0时使用1M，MaxFields为0时使用1000）
+ */
+type UploadConfig struct {
+	MaxFileSize  int64            // 单个文件的最大字节数
+	MaxTotalSize int64            // 请求体的最大字节数，Content-Length超出时不读取请求体直接响应413
+	MaxFieldSize int64            // 单个普通字段的最大字节数
+	MaxFields    int              // 最多的普通字段数
+	MaxFiles     int              // 最多的文件数
+	AllowedTypes []string         // 允许的MIME类型，按文件开头的magic bytes识别，支持“image/*”，为空时不限制
+	Hash         func() hash.Hash // 计算校验和的算法，为nil时使用sha256
+}
+
+/**
+ * UploadedFile
+ * @Description: 处理完成的文件信息
+ */
+type UploadedFile struct {
+	FieldName   string
+	Filename    string // 清理后的文件名，见SanitizeFilename
+	RawFilename string // 客户端提交的原始文件名，可能包含路径
+	ContentType string // 由文件内容识别的MIME类型
+	Size        int64
+	Checksum    string // 十六进制的校验和
+	Path        string // SaveUploads保存的路径
+}
+
+/**
+ * UploadResult
+ * @Description: 上传请求的处理结果
+ */
+type UploadResult struct {
+	Values url.Values // 普通表单字段
+	Files  []*UploadedFile
+}
+
+/**
+ * FilePart
+ * @Description: 正在接收的文件，作为io.Reader读取文件内容，
+ * 读取时校验大小并计算校验和，超出MaxFileSize时返回ErrUploadTooLarge
+ */
+type FilePart struct {
+	FieldName   string
+	Filename    string // 清理后的文件名
+	RawFilename string
+	ContentType string // 由文件内容识别的MIME类型
+	Header      textproto.MIMEHeader
+	r           io.Reader
+	hash        hash.Hash
+	size        int64
+	maxSize     int64
+}
+
+func (p *FilePart) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if err != nil && err != io.EOF {
+		err = uploadError(err)
+	}
+	p.size += int64(n)
+	p.hash.Write(b[:n])
+	if p.maxSize > 0 && p.size > p.maxSize {
+		return n, fmt.Errorf("file [%s] exceeds %d bytes: %w", p.RawFilename, p.maxSize, ErrUploadTooLarge)
+	}
+	return n, err
+}
+
+/**
+ * Upload
+ * @Author：Jack-Z
+ * @Description: 流式处理multipart上传，按顺序处理每个part，文件交给handler读取，不会把整个表单读入内存或临时文件。
+ * 超出大小或数量限制时响应413，文件类型不允许时响应415，请求格式错误时响应400，handler的错误原样返回
+ * @receiver c
+ * @param config
+ * @param handler 处理一个文件，未读完的内容会被丢弃（仍会校验大小）
+ * @return *UploadResult
+ * @return error
+ */
+func (c *Context) Upload(config UploadConfig, handler func(part *FilePart) error) (*UploadResult, error) {
+	result, err := c.upload(config, handler)
+	if status := uploadStatus(err); status != 0 {
+		c.W.WriteHeader(status)
+	}
+	return result, err
+}
+
+func (c *Context) upload(config UploadConfig, handler func(part *FilePart) error) (*UploadResult, error) {
+	if config.MaxTotalSize > 0 {
+		// 提前拒绝，不读取请求体
+		if c.R.ContentLength > config.MaxTotalSize {
+			return nil, fmt.Errorf("request body exceeds %d bytes: %w", config.MaxTotalSize, ErrUploadTooLarge)
+		}
+		c.R.Body = http.MaxBytesReader(c.W.Unwrap(), c.R.Body, config.MaxTotalSize)
+	}
+	if config.MaxFieldSize <= 0 {
+		config.MaxFieldSize = defaultMaxFieldSize
+	}
+	if config.MaxFields <= 0 {
+		config.MaxFields = defaultMaxFields
+	}
+	if config.Hash == nil {
+		config.Hash = sha256.New
+	}
+	reader, err := c.R.MultipartReader()
+	if err != nil {
+		return nil, uploadError(err)
+	}
+
+	result := &UploadResult{Values: make(url.Values)}
+	fields := 0
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, uploadError(err)
+		}
+		if part.FileName() == "" {
+			if fields >= config.MaxFields {
+				part.Close()
+				return result, fmt.Errorf("more than %d fields: %w", config.MaxFields, ErrTooManyFields)
+			}
+			fields++
+			value, err := readField(part, config.MaxFieldSize)
+			part.Close()
+			if err != nil {
+				return result, uploadError(err)
+			}
+			result.Values.Add(part.FormName(), value)
+			continue
+		}
+		if config.MaxFiles > 0 && len(result.Files) >= config.MaxFiles {
+			part.Close()
+			return result, fmt.Errorf("more than %d files: %w", config.MaxFiles, ErrTooManyFiles)
+		}
+		file, err := receiveFile(part, config, handler)
+		part.Close()
+		if err != nil {
+			return result, err
+		}
+		result.Files = append(result.Files, file)
+	}
+}
+
+// receiveFile 识别文件类型后交给handler，再读完剩余内容以得到大小和校验和
+func receiveFile(part *multipart.Part, config UploadConfig, handler func(part *FilePart) error) (*UploadedFile, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, uploadError(err)
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !allowedType(contentType, config.AllowedTypes) {
+		return nil, fmt.Errorf("file [%s] of type %s: %w", part.FileName(), contentType, ErrUnsupportedMediaType)
+	}
+
+	fp := &FilePart{
+		FieldName:   part.FormName(),
+		Filename:    SanitizeFilename(part.FileName()),
+		RawFilename: rawFilename(part),
+		ContentType: contentType,
+		Header:      part.Header,
+		r:           io.MultiReader(bytes.NewReader(head), part),
+		hash:        config.Hash(),
+		maxSize:     config.MaxFileSize,
+	}
+	if handler != nil {
+		if err := handler(fp); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := io.Copy(io.Discard, fp); err != nil {
+		return nil, err
+	}
+	return &UploadedFile{
+		FieldName:   fp.FieldName,
+		Filename:    fp.Filename,
+		RawFilename: fp.RawFilename,
+		ContentType: fp.ContentType,
+		Size:        fp.size,
+		Checksum:    hex.EncodeToString(fp.hash.Sum(nil)),
+	}, nil
+}
+
+// rawFilename Content-Disposition中的原始文件名，part.FileName()会去掉其中的路径
+func rawFilename(part *multipart.Part) string {
+	_, params, err := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+	if err != nil || params["filename"] == "" {
+		return part.FileName()
+	}
+	return params["filename"]
+}
+
+func readField(part *multipart.Part, maxSize int64) (string, error) {
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(part, maxSize+1))
+	if err != nil {
+		return "", uploadError(err)
+	}
+	if n > maxSize {
+		return "", fmt.Errorf("field [%s] exceeds %d bytes: %w", part.FormName(), maxSize, ErrUploadTooLarge)
+	}
+	return buf.String(), nil
+}
+
+// allowedType contentType是否在allowed中，支持“type/*”和“*/*”
+func allowedType(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	typ, _, _ := strings.Cut(contentType, "/")
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == contentType || a == "*/*" || a == typ+"/*" {
+			return true
+		}
+	}
+	return false
+}
+
+// uploadError 转换读取请求体的错误：超出MaxBytesReader限制时为ErrUploadTooLarge，其他为MalformedUploadError
+func uploadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	var malformedErr *MalformedUploadError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return fmt.Errorf("request body exceeds %d bytes: %w", maxBytesErr.Limit, ErrUploadTooLarge)
+	case errors.Is(err, multipart.ErrMessageTooLarge), errors.As(err, &malformedErr):
+		return err
+	}
+	return &MalformedUploadError{Err: err}
+}
+
+// uploadStatus 上传错误对应的状态码，handler返回的其他错误为0
+func uploadStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	var malformedErr *MalformedUploadError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrUploadTooLarge), errors.Is(err, ErrTooManyFiles), errors.Is(err, ErrTooManyFields),
+		errors.As(err, &maxBytesErr), errors.Is(err, multipart.ErrMessageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.As(err, &malformedErr), errors.Is(err, http.ErrNotMultipart), errors.Is(err, http.ErrMissingBoundary),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return http.StatusBadRequest
+	}
+	return 0
+}
+
+/**
+ * SaveUploads
+ * @Author：Jack-Z
+ * @Description: 流式接收上传并把文件保存到dir，文件名经过清理，同名时追加序号，不会覆盖已有文件；
+ * 出错时删除本次已保存的文件
+ * @receiver c
+ * @param config
+ * @param dir
+ * @return *UploadResult
+ * @return error
+ */
+func (c *Context) SaveUploads(config UploadConfig, dir string) (*UploadResult, error) {
+	var saved []string
+	result, err := c.Upload(config, func(part *FilePart) error {
+		f, err := createUnique(dir, part.Filename)
+		if err != nil {
+			return err
+		}
+		saved = append(saved, f.Name())
+		_, err = io.Copy(f, part)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	})
+	if err != nil {
+		for _, name := range saved {
+			os.Remove(name)
+		}
+		return result, err
+	}
+	for i, file := range result.Files {
+		file.Path = saved[i]
+	}
+	return result, nil
+}
+
+// createUnique 在dir中创建文件，已存在时依次尝试“name-1.ext”、“name-2.ext”……
+func createUnique(dir, filename string) (*os.File, error) {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	for i := 0; ; i++ {
+		name := filename
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !errors.Is(err, os.ErrExist) || i >= 1000 {
+			return f, err
+		}
+	}
+}
+
+// windowsReserved windows保留的设备名，不能作为文件名
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+/**
+ * SanitizeFilename
+ * @Author：Jack-Z
+ * @Description: 清理客户端提交的文件名，只保留最后一段路径，去掉控制字符和不能用于文件名的字符，
+ * 去掉首尾的“.”和空格，避免目录穿越和隐藏文件；结果为空时返回“file”
+ * @param name
+ * @return string
+ */
+func SanitizeFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == utf8.RuneError || strings.ContainsRune(`<>:"|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, ". ")
+	if name == "" {
+		return "file"
+	}
+	stem, _, _ := strings.Cut(name, ".")
+	if windowsReserved[strings.ToUpper(stem)] {
+		name = "_" + name
+	}
+	if len(name) > maxFilenameLen {
+		// 截断时保留扩展名，并避免截断多字节字符
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		stem := name[:maxFilenameLen-len(ext)]
+		for !utf8.ValidString(stem) {
+			stem = stem[:len(stem)-1]
+		}
+		name = stem + ext
+	}
+	return name
+}
@@ -0,0 +1,136 @@
+package go_rookie
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	cases := map[string]string{
+		"../../etc/passwd":                "passwd",
+		`C:\Users\a\报告.pdf`:               "报告.pdf",
+		"..":                              "file",
+		".htaccess":                       "htaccess",
+		"con.txt":                         "_con.txt",
+		"a<b>:c|d?.txt":                   "a_b__c_d_.txt",
+		"name\x00.txt":                    "name_.txt",
+		strings.Repeat("长", 100) + ".txt": strings.Repeat("长", 83) + ".txt",
+	}
+	for name, want := range cases {
+		if got := SanitizeFilename(name); got != want {
+			t.Errorf("%q: want %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestSaveUploads(t *testing.T) {
+	dir := t.TempDir()
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{1}, 100)...)
+	config := UploadConfig{MaxFileSize: 1024, MaxTotalSize: 4096, AllowedTypes: []string{"image/*"}}
+	engine := New()
+	upload := func(filename string, content []byte, contentLength int64) (*UploadResult, int, error) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		mw.WriteField("title", "avatar")
+		fw, _ := mw.CreateFormFile("file", filename)
+		fw.Write(content)
+		mw.Close()
+		r := httptest.NewRequest("POST", "/", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		if contentLength > 0 {
+			r.ContentLength = contentLength
+		}
+		w := httptest.NewRecorder()
+		ctx := engine.NewContext(w, r)
+		result, err := ctx.SaveUploads(config, dir)
+		ctx.W.WriteHeaderNow()
+		return result, w.Code, err
+	}
+
+	for i := 0; i < 2; i++ {
+		result, _, err := upload("../a.png", png, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(png)
+		file := result.Files[0]
+		want := []string{"a.png", "a-1.png"}[i]
+		if result.Values.Get("title") != "avatar" || file.RawFilename != "../a.png" || file.ContentType != "image/png" || file.Size != int64(len(png)) ||
+			file.Checksum != hex.EncodeToString(sum[:]) || file.Path != filepath.Join(dir, want) {
+			t.Fatalf("unexpected result %+v", file)
+		}
+		if data, _ := os.ReadFile(file.Path); !bytes.Equal(data, png) {
+			t.Fatal("saved content mismatch")
+		}
+	}
+
+	if _, code, err := upload("a.png", []byte("<html>not an image</html>"), 0); !errors.Is(err, ErrUnsupportedMediaType) || code != 415 {
+		t.Fatalf("unexpected result %d %v", code, err)
+	}
+	if _, code, err := upload("big.png", append(png, bytes.Repeat([]byte{1}, 2048)...), 0); !errors.Is(err, ErrUploadTooLarge) || code != 413 {
+		t.Fatalf("unexpected result %d %v", code, err)
+	}
+	if _, code, err := upload("a.png", png, 1<<20); !errors.Is(err, ErrUploadTooLarge) || code != 413 {
+		t.Fatalf("unexpected result %d %v", code, err)
+	}
+	config.MaxFields = 1
+	if _, code, err := upload("a.png", png, 0); err != nil || code != 200 {
+		t.Fatalf("unexpected result %d %v", code, err)
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("a", "1")
+	mw.WriteField("b", "2")
+	mw.Close()
+	r := httptest.NewRequest("POST", "/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	if _, err := engine.NewContext(httptest.NewRecorder(), r).Upload(config, nil); !errors.Is(err, ErrTooManyFields) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// 失败的上传不应留下文件
+	if entries, _ := os.ReadDir(dir); len(entries) != 3 {
+		t.Fatalf("unexpected files %v", entries)
+	}
+}
+
+func TestUploadMalformed(t *testing.T) {
+	engine := New()
+	errHandler := errors.New("handler failed")
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		handlerErr  error
+		status      int
+	}{
+		{"not multipart", "text/plain", "a=1", nil, 400},
+		{"missing boundary", "multipart/form-data", "", nil, 400},
+		// 缺少结束分隔符
+		{"truncated", "multipart/form-data; boundary=xyz", "--xyz\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n1", nil, 400},
+		{"bad part header", "multipart/form-data; boundary=xyz", "--xyz\r\nbroken header\r\n\r\n1\r\n--xyz--\r\n", nil, 400},
+		// handler返回的错误不改变状态码
+		{"handler", "multipart/form-data; boundary=xyz", "--xyz\r\nContent-Disposition: form-data; name=\"f\"; filename=\"a.txt\"\r\n\r\nhello\r\n--xyz--\r\n", errHandler, 200},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", tt.contentType)
+		w := httptest.NewRecorder()
+		ctx := engine.NewContext(w, r)
+		_, err := ctx.Upload(UploadConfig{}, func(part *FilePart) error {
+			return tt.handlerErr
+		})
+		ctx.W.WriteHeaderNow()
+		var malformedErr *MalformedUploadError
+		if w.Code != tt.status || err == nil || errors.As(err, &malformedErr) != (tt.status == 400) {
+			t.Errorf("%s: unexpected result %d %v", tt.name, w.Code, err)
+		}
+	}
+}
@@ -22,6 +22,31 @@ func SubStringLast(str string, substr string) string {
 	return str[index+len(substr):]
 }
 
+/**
+ * joinPaths
+ * @Author：Jack-Z
+ * @Description: 拼接分组前缀和路由，保证结果以“/”开头，并保留路由末尾的“/”
+ * @param prefix
+ * @param path
+ * @return string
+ */
+func joinPaths(prefix, path string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix = "/" + prefix
+	}
+	if path == "" {
+		if prefix == "" {
+			return "/"
+		}
+		return prefix
+	}
+	if path[0] != '/' {
+		path = "/" + path
+	}
+	return prefix + path
+}
+
 /**
  * isASCII
  * @Author：Jack-Z
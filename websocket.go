@@ -0,0 +1,60 @@
+package go_rookie
+
+import (
+	"bufio"
+	"github.com/Jack-ZL/go_rookie/websocket"
+	"net"
+	"net/http"
+)
+
+// WebSocketHandler websocket连接的处理函数，返回后连接被关闭
+type WebSocketHandler func(ctx *Context, conn *websocket.Conn)
+
+/**
+ * WebSocket
+ * @Author：Jack-Z
+ * @Description: 注册websocket路由，全局、分组和路由中间件（如鉴权）在升级之前执行，
+ * 中间件中断处理链时不会升级
+ * @receiver r
+ * @param name
+ * @param handler
+ * @param middlewareFunc
+ * @return *Route
+ */
+func (r *routerGroup) WebSocket(name string, handler WebSocketHandler, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.Get(name, func(ctx *Context) {
+		conn, err := ctx.Upgrade()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(ctx, conn)
+	}, middlewareFunc...)
+}
+
+/**
+ * Upgrade
+ * @Author：Jack-Z
+ * @Description: 把当前请求升级为websocket连接，失败时已经写出错误响应
+ * @receiver c
+ * @param upgrader 为空时使用Engine.Upgrader
+ * @return *websocket.Conn
+ * @return error
+ */
+func (c *Context) Upgrade(upgrader ...*websocket.Upgrader) (*websocket.Conn, error) {
+	u := &c.engine.Upgrader
+	if len(upgrader) > 0 {
+		u = upgrader[0]
+	}
+	return u.Upgrade(switchingWriter{c.W}, c.R, nil)
+}
+
+// switchingWriter 握手校验通过、接管连接时才记录101状态码供日志使用，握手失败时保留错误响应的状态码
+type switchingWriter struct {
+	ResponseWriter
+}
+
+func (w switchingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.WriteHeader(http.StatusSwitchingProtocols)
+	return w.ResponseWriter.Hijack()
+}
@@ -0,0 +1,139 @@
+package websocket
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	minCompressionLevel     = flate.HuffmanOnly
+	maxCompressionLevel     = flate.BestCompression
+	defaultCompressionLevel = flate.BestSpeed
+)
+
+// flateTail 压缩数据flush后末尾的同步标记，发送时去掉，接收时补上
+var flateTail = []byte{0x00, 0x00, 0xff, 0xff}
+
+// flateReaderTail 补上同步标记和一个空的结束块，避免解压时返回io.ErrUnexpectedEOF
+var flateReaderTail = []byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff}
+
+var flateWriterPools [maxCompressionLevel - minCompressionLevel + 1]sync.Pool
+
+var flateReaderPool = sync.Pool{New: func() any {
+	return flate.NewReader(nil)
+}}
+
+func isValidCompressionLevel(level int) bool {
+	return level >= minCompressionLevel && level <= maxCompressionLevel
+}
+
+// newFlateWriter 从pool中获取压缩器，没有上下文复用（no_context_takeover），每条消息重新开始
+func newFlateWriter(w io.Writer, level int) (*flate.Writer, error) {
+	pool := &flateWriterPools[level-minCompressionLevel]
+	if fw, ok := pool.Get().(*flate.Writer); ok {
+		fw.Reset(w)
+		return fw, nil
+	}
+	return flate.NewWriter(w, level)
+}
+
+func putFlateWriter(fw *flate.Writer, level int) {
+	flateWriterPools[level-minCompressionLevel].Put(fw)
+}
+
+/**
+ * compress
+ * @Author：Jack-Z
+ * @Description: 按permessage-deflate压缩一条消息
+ * @param data
+ * @param level
+ * @return []byte
+ * @return error
+ */
+func compress(data []byte, level int) ([]byte, error) {
+	var buf bytes.Buffer
+	fw, err := newFlateWriter(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	defer putFlateWriter(fw, level)
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := fw.Flush(); err != nil {
+		return nil, err
+	}
+	return trimFlateTail(buf.Bytes()), nil
+}
+
+/**
+ * decompress
+ * @Author：Jack-Z
+ * @Description: 按permessage-deflate解压一条消息
+ * @param data
+ * @param limit 解压后的最大字节数
+ * @return []byte
+ * @return error
+ */
+func decompress(data []byte, limit int64) ([]byte, error) {
+	fr := flateReaderPool.Get().(io.ReadCloser)
+	defer flateReaderPool.Put(fr)
+	if err := fr.(flate.Resetter).Reset(io.MultiReader(bytes.NewReader(data), bytes.NewReader(flateReaderTail)), nil); err != nil {
+		return nil, err
+	}
+	out, err := io.ReadAll(io.LimitReader(fr, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(out)) > limit {
+		return nil, ErrReadLimit
+	}
+	return out, nil
+}
+
+func trimFlateTail(data []byte) []byte {
+	if bytes.HasSuffix(data, flateTail) {
+		return data[:len(data)-len(flateTail)]
+	}
+	return data
+}
+
+/**
+ * negotiateCompression
+ * @Author：Jack-Z
+ * @Description: 从Sec-WebSocket-Extensions中选择可以接受的permessage-deflate，
+ * 服务端固定使用32K窗口且不复用上下文，要求更小server_max_window_bits的请求不予接受
+ * @param headers 请求头Sec-WebSocket-Extensions的所有值
+ * @return string 响应的扩展，为空时不启用压缩
+ */
+func negotiateCompression(headers []string) string {
+	for _, header := range headers {
+		for _, offer := range strings.Split(header, ",") {
+			params := strings.Split(offer, ";")
+			if strings.TrimSpace(params[0]) != "permessage-deflate" {
+				continue
+			}
+			ok := true
+			for _, param := range params[1:] {
+				key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				value = strings.Trim(strings.TrimSpace(value), `"`)
+				switch strings.TrimSpace(key) {
+				case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+				case "server_max_window_bits":
+					if value != "15" {
+						ok = false
+					}
+				default:
+					ok = false
+				}
+			}
+			if ok {
+				return "permessage-deflate; server_no_context_takeover; client_no_context_takeover"
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,738 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 消息类型，与帧的opcode一致
+const (
+	continuationFrame = 0
+	TextMessage       = 1
+	BinaryMessage     = 2
+	CloseMessage      = 8
+	PingMessage       = 9
+	PongMessage       = 10
+)
+
+// 关闭状态码，见RFC 6455 7.4.1
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseMandatoryExtension      = 1010
+	CloseInternalServerErr       = 1011
+	CloseServiceRestart          = 1012
+	CloseTryAgainLater           = 1013
+	CloseTLSHandshake            = 1015
+)
+
+const (
+	finalBit = 0x80
+	rsv1Bit  = 0x40
+	rsv2Bit  = 0x20
+	rsv3Bit  = 0x10
+	maskBit  = 0x80
+
+	maxControlPayload      = 125
+	defaultWriteBufferSize = 4096
+	defaultReadLimit       = 32 << 20
+	readChunkSize          = 32 << 10
+)
+
+var (
+	ErrCloseSent = errors.New("websocket: close sent")
+	ErrReadLimit = errors.New("websocket: read limit exceeded")
+)
+
+/**
+ * CloseError
+ * @Description: 收到对方的关闭帧后，读取方法返回的错误
+ */
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return "websocket: close " + strconv.Itoa(e.Code) + " " + e.Text
+}
+
+/**
+ * IsCloseError
+ * @Author：Jack-Z
+ * @Description: err是否为指定状态码的CloseError，不传状态码时只判断是否为CloseError
+ * @param err
+ * @param codes
+ * @return bool
+ */
+func IsCloseError(err error, codes ...int) bool {
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) {
+		return false
+	}
+	if len(codes) == 0 {
+		return true
+	}
+	for _, code := range codes {
+		if closeErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * Conn
+ * @Description: websocket连接。同一时间只能有一个goroutine读、一个goroutine写消息，
+ * WriteControl、Close可以与其他方法并发调用
+ */
+type Conn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	isServer    bool
+	subprotocol string
+
+	writeMu         sync.Mutex
+	closeSent       bool
+	writeBufferSize int
+	compression     bool // 是否协商了permessage-deflate
+	writeCompress   bool // 写消息时是否压缩
+	compressLevel   int
+
+	readLimit   int64
+	readErr     error
+	handlePing  func(appData string) error
+	handlePong  func(appData string) error
+	handleClose func(code int, text string) error
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, isServer bool, writeBufferSize int) *Conn {
+	if br == nil {
+		br = bufio.NewReader(conn)
+	}
+	if writeBufferSize <= 0 {
+		writeBufferSize = defaultWriteBufferSize
+	}
+	c := &Conn{
+		conn:            conn,
+		br:              br,
+		isServer:        isServer,
+		writeBufferSize: writeBufferSize,
+		compressLevel:   defaultCompressionLevel,
+		readLimit:       defaultReadLimit,
+	}
+	c.SetPingHandler(nil)
+	c.SetPongHandler(nil)
+	c.SetCloseHandler(nil)
+	return c
+}
+
+// Subprotocol 协商的子协议
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// UnderlyingConn 底层的网络连接
+func (c *Conn) UnderlyingConn() net.Conn {
+	return c.conn
+}
+
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetReadDeadline 设置读超时，超时后连接不可再用
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline 设置写超时，超时后连接不可再用
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// SetReadLimit 设置单条消息（解压后）的最大字节数，超出时以1009关闭连接，limit<=0时使用默认的32MB
+func (c *Conn) SetReadLimit(limit int64) {
+	if limit <= 0 {
+		limit = defaultReadLimit
+	}
+	c.readLimit = limit
+}
+
+// EnableWriteCompression 协商了压缩时，是否压缩发送的消息
+func (c *Conn) EnableWriteCompression(enable bool) {
+	c.writeCompress = enable && c.compression
+}
+
+// SetCompressionLevel 设置压缩级别，见compress/flate
+func (c *Conn) SetCompressionLevel(level int) error {
+	if !isValidCompressionLevel(level) {
+		return errors.New("websocket: invalid compression level")
+	}
+	c.compressLevel = level
+	return nil
+}
+
+/**
+ * SetPingHandler
+ * @Author：Jack-Z
+ * @Description: 设置收到ping时的处理函数，为nil时回复pong
+ * @receiver c
+ * @param h
+ */
+func (c *Conn) SetPingHandler(h func(appData string) error) {
+	if h == nil {
+		h = func(appData string) error {
+			err := c.WriteControl(PongMessage, []byte(appData))
+			if errors.Is(err, ErrCloseSent) {
+				return nil
+			}
+			return err
+		}
+	}
+	c.handlePing = h
+}
+
+// SetPongHandler 设置收到pong时的处理函数，常用于延长读超时，为nil时忽略
+func (c *Conn) SetPongHandler(h func(appData string) error) {
+	if h == nil {
+		h = func(string) error { return nil }
+	}
+	c.handlePong = h
+}
+
+/**
+ * SetCloseHandler
+ * @Author：Jack-Z
+ * @Description: 设置收到关闭帧时的处理函数，为nil时回复相同状态码的关闭帧
+ * @receiver c
+ * @param h
+ */
+func (c *Conn) SetCloseHandler(h func(code int, text string) error) {
+	if h == nil {
+		h = func(code int, text string) error {
+			var payload []byte
+			if code != CloseNoStatusReceived {
+				payload = FormatCloseMessage(code, "")
+			}
+			err := c.WriteControl(CloseMessage, payload)
+			if errors.Is(err, ErrCloseSent) {
+				return nil
+			}
+			return err
+		}
+	}
+	c.handleClose = h
+}
+
+/**
+ * ReadMessage
+ * @Author：Jack-Z
+ * @Description: 读取一条完整的消息，合并分片、解压缩并处理期间收到的控制帧。
+ * 收到关闭帧时返回*CloseError，出错后连接不可再读
+ * @receiver c
+ * @return int TextMessage或BinaryMessage
+ * @return []byte
+ * @return error
+ */
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	if c.readErr != nil {
+		return -1, nil, c.readErr
+	}
+	messageType, data, err := c.readMessage()
+	if err != nil {
+		c.readErr = err
+		return -1, nil, err
+	}
+	return messageType, data, nil
+}
+
+func (c *Conn) readMessage() (int, []byte, error) {
+	messageType := -1
+	compressed := false
+	var data []byte
+	for {
+		h, payload, err := c.readFrame(c.readLimit - int64(len(data)))
+		if err != nil {
+			return -1, nil, err
+		}
+		switch h.opcode {
+		case PingMessage:
+			if err := c.handlePing(string(payload)); err != nil {
+				return -1, nil, err
+			}
+			continue
+		case PongMessage:
+			if err := c.handlePong(string(payload)); err != nil {
+				return -1, nil, err
+			}
+			continue
+		case CloseMessage:
+			code, text, err := parseCloseMessage(payload)
+			if err != nil {
+				return -1, nil, c.fail(CloseProtocolError, err)
+			}
+			if err := c.handleClose(code, text); err != nil {
+				return -1, nil, err
+			}
+			return -1, nil, &CloseError{Code: code, Text: text}
+		case TextMessage, BinaryMessage:
+			if messageType != -1 {
+				return -1, nil, c.fail(CloseProtocolError, errors.New("websocket: data frame in fragmented message"))
+			}
+			messageType = h.opcode
+			compressed = h.rsv1
+		case continuationFrame:
+			if messageType == -1 {
+				return -1, nil, c.fail(CloseProtocolError, errors.New("websocket: unexpected continuation frame"))
+			}
+			if h.rsv1 {
+				return -1, nil, c.fail(CloseProtocolError, errors.New("websocket: rsv1 set on continuation frame"))
+			}
+		}
+		data = append(data, payload...)
+		if h.fin {
+			break
+		}
+	}
+	if compressed {
+		var err error
+		if data, err = decompress(data, c.readLimit); err != nil {
+			if errors.Is(err, ErrReadLimit) {
+				return -1, nil, c.fail(CloseMessageTooBig, err)
+			}
+			return -1, nil, c.fail(CloseInvalidFramePayloadData, err)
+		}
+	}
+	if messageType == TextMessage && !utf8.Valid(data) {
+		return -1, nil, c.fail(CloseInvalidFramePayloadData, errors.New("websocket: invalid utf8 in text message"))
+	}
+	return messageType, data, nil
+}
+
+type frameHeader struct {
+	fin    bool
+	rsv1   bool
+	opcode int
+}
+
+/**
+ * readFrame
+ * @Author：Jack-Z
+ * @Description: 读取并校验一个帧，返回去掉掩码后的payload
+ * @receiver c
+ * @param limit 数据帧payload的最大字节数，即当前消息还能读取的字节数
+ * @return frameHeader
+ * @return []byte
+ * @return error
+ */
+func (c *Conn) readFrame(limit int64) (frameHeader, []byte, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(c.br, buf[:2]); err != nil {
+		return frameHeader{}, nil, err
+	}
+	h := frameHeader{
+		fin:    buf[0]&finalBit != 0,
+		rsv1:   buf[0]&rsv1Bit != 0,
+		opcode: int(buf[0] & 0x0f),
+	}
+	if buf[0]&(rsv2Bit|rsv3Bit) != 0 {
+		return h, nil, c.fail(CloseProtocolError, errors.New("websocket: unexpected rsv bits"))
+	}
+	masked := buf[1]&maskBit != 0
+	length := int64(buf[1] & 0x7f)
+
+	switch h.opcode {
+	case continuationFrame, TextMessage, BinaryMessage:
+		if h.rsv1 && !c.compression {
+			return h, nil, c.fail(CloseProtocolError, errors.New("websocket: rsv1 set without compression"))
+		}
+	case CloseMessage, PingMessage, PongMessage:
+		if !h.fin || length > maxControlPayload || h.rsv1 {
+			return h, nil, c.fail(CloseProtocolError, errors.New("websocket: invalid control frame"))
+		}
+	default:
+		return h, nil, c.fail(CloseProtocolError, fmt.Errorf("websocket: unknown opcode %d", h.opcode))
+	}
+	// 客户端发送的帧必须带掩码，服务端发送的帧不能带掩码
+	if masked != c.isServer {
+		return h, nil, c.fail(CloseProtocolError, errors.New("websocket: incorrect mask flag"))
+	}
+
+	switch length {
+	case 126:
+		if _, err := io.ReadFull(c.br, buf[:2]); err != nil {
+			return h, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(buf[:2]))
+	case 127:
+		if _, err := io.ReadFull(c.br, buf[:8]); err != nil {
+			return h, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(buf[:8]))
+		if length < 0 {
+			return h, nil, c.fail(CloseProtocolError, errors.New("websocket: invalid payload length"))
+		}
+	}
+	// 控制帧只受125字节的限制，不占用分片之间剩余的消息长度
+	if h.opcode < CloseMessage && length > limit {
+		return h, nil, c.fail(CloseMessageTooBig, ErrReadLimit)
+	}
+
+	var maskKey [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, maskKey[:]); err != nil {
+			return h, nil, err
+		}
+	}
+	// 按块读取，不按帧头声明的长度一次性分配
+	var payload []byte
+	for int64(len(payload)) < length {
+		n := length - int64(len(payload))
+		if n > readChunkSize {
+			n = readChunkSize
+		}
+		start := len(payload)
+		payload = append(payload, make([]byte, n)...)
+		if _, err := io.ReadFull(c.br, payload[start:]); err != nil {
+			return h, nil, err
+		}
+	}
+	if masked {
+		maskBytes(maskKey, payload)
+	}
+	return h, payload, nil
+}
+
+// fail 以code发送关闭帧并返回err
+func (c *Conn) fail(code int, err error) error {
+	_ = c.WriteControl(CloseMessage, FormatCloseMessage(code, ""))
+	return err
+}
+
+/**
+ * WriteMessage
+ * @Author：Jack-Z
+ * @Description: 发送一条消息（单帧），协商了压缩时按设置压缩
+ * @receiver c
+ * @param messageType TextMessage、BinaryMessage，或控制消息
+ * @param data
+ * @return error
+ */
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType == CloseMessage || messageType == PingMessage || messageType == PongMessage {
+		return c.WriteControl(messageType, data)
+	}
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: unknown message type %d", messageType)
+	}
+	if c.writeCompress {
+		compressed, err := compress(data, c.compressLevel)
+		if err != nil {
+			return err
+		}
+		return c.writeFrame(messageType, true, true, compressed)
+	}
+	return c.writeFrame(messageType, true, false, data)
+}
+
+/**
+ * NextWriter
+ * @Author：Jack-Z
+ * @Description: 以流的方式发送一条消息，缓冲区写满时发送一个分片，Close时发送最后一个分片
+ * @receiver c
+ * @param messageType TextMessage或BinaryMessage
+ * @return io.WriteCloser
+ * @return error
+ */
+func (c *Conn) NextWriter(messageType int) (io.WriteCloser, error) {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return nil, fmt.Errorf("websocket: unknown message type %d", messageType)
+	}
+	w := &messageWriter{c: c, opcode: messageType, compress: c.writeCompress, level: c.compressLevel}
+	if w.compress {
+		fw, err := newFlateWriter(&w.buf, w.level)
+		if err != nil {
+			return nil, err
+		}
+		w.flate = fw
+	}
+	return w, nil
+}
+
+/**
+ * WriteControl
+ * @Author：Jack-Z
+ * @Description: 发送控制消息（close、ping、pong），payload不能超过125字节，
+ * 发送关闭帧后不能再发送其他帧
+ * @receiver c
+ * @param messageType
+ * @param data
+ * @return error
+ */
+func (c *Conn) WriteControl(messageType int, data []byte) error {
+	if messageType != CloseMessage && messageType != PingMessage && messageType != PongMessage {
+		return fmt.Errorf("websocket: %d is not a control message", messageType)
+	}
+	if len(data) > maxControlPayload {
+		return errors.New("websocket: control payload too long")
+	}
+	return c.writeFrame(messageType, true, false, data)
+}
+
+/**
+ * WriteClose
+ * @Author：Jack-Z
+ * @Description: 发送关闭帧，之后对方回复关闭帧时ReadMessage返回*CloseError
+ * @receiver c
+ * @param code
+ * @param text
+ * @return error
+ */
+func (c *Conn) WriteClose(code int, text string) error {
+	return c.WriteControl(CloseMessage, FormatCloseMessage(code, text))
+}
+
+// Ping 发送ping
+func (c *Conn) Ping(data []byte) error {
+	return c.WriteControl(PingMessage, data)
+}
+
+/**
+ * Close
+ * @Author：Jack-Z
+ * @Description: 没有发送过关闭帧时先发送1000，然后关闭底层连接
+ * @receiver c
+ * @return error
+ */
+func (c *Conn) Close() error {
+	c.writeMu.Lock()
+	closeSent := c.closeSent
+	c.writeMu.Unlock()
+	if !closeSent {
+		_ = c.WriteClose(CloseNormalClosure, "")
+	}
+	return c.conn.Close()
+}
+
+// WriteJSON 以text消息发送v的json编码
+func (c *Conn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(TextMessage, data)
+}
+
+// ReadJSON 读取一条消息并按json解码到v
+func (c *Conn) ReadJSON(v any) error {
+	_, data, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+/**
+ * writeFrame
+ * @Author：Jack-Z
+ * @Description: 编码并发送一个帧，客户端发送时加掩码
+ * @receiver c
+ * @param opcode
+ * @param fin
+ * @param rsv1
+ * @param payload
+ * @return error
+ */
+func (c *Conn) writeFrame(opcode int, fin, rsv1 bool, payload []byte) error {
+	header := make([]byte, 0, 14+len(payload))
+	b0 := byte(opcode)
+	if fin {
+		b0 |= finalBit
+	}
+	if rsv1 {
+		b0 |= rsv1Bit
+	}
+	header = append(header, b0)
+
+	var b1 byte
+	if !c.isServer {
+		b1 = maskBit
+	}
+	switch length := len(payload); {
+	case length <= 125:
+		header = append(header, b1|byte(length))
+	case length <= 0xffff:
+		header = append(header, b1|126)
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header = append(header, b1|127)
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+	frame := header
+	if c.isServer {
+		frame = append(frame, payload...)
+	} else {
+		var maskKey [4]byte
+		if _, err := rand.Read(maskKey[:]); err != nil {
+			return err
+		}
+		frame = append(frame, maskKey[:]...)
+		start := len(frame)
+		frame = append(frame, payload...)
+		maskBytes(maskKey, frame[start:])
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrCloseSent
+	}
+	if opcode == CloseMessage {
+		c.closeSent = true
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+/**
+ * messageWriter
+ * @Description: NextWriter返回的分片写入器
+ */
+type messageWriter struct {
+	c        *Conn
+	opcode   int // 第一个分片为消息类型，之后为continuationFrame
+	buf      bytes.Buffer
+	compress bool
+	level    int
+	flate    *flate.Writer
+	started  bool
+	closed   bool
+}
+
+func (w *messageWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("websocket: write to closed writer")
+	}
+	var err error
+	if w.compress {
+		_, err = w.flate.Write(p)
+	} else {
+		_, err = w.buf.Write(p)
+	}
+	if err != nil {
+		return 0, err
+	}
+	// 压缩时保留末尾4字节，结束时需要去掉flush产生的0x00 0x00 0xff 0xff
+	keep := 0
+	if w.compress {
+		keep = 4
+	}
+	for w.buf.Len()-keep >= w.c.writeBufferSize {
+		if err := w.flushFrame(false, w.buf.Next(w.c.writeBufferSize)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *messageWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	data := w.buf.Bytes()
+	if w.compress {
+		if err := w.flate.Flush(); err != nil {
+			return err
+		}
+		putFlateWriter(w.flate, w.level)
+		w.flate = nil
+		data = trimFlateTail(w.buf.Bytes())
+	}
+	return w.flushFrame(true, data)
+}
+
+func (w *messageWriter) flushFrame(fin bool, data []byte) error {
+	rsv1 := w.compress && !w.started
+	opcode := w.opcode
+	if w.started {
+		opcode = continuationFrame
+	}
+	w.started = true
+	return w.c.writeFrame(opcode, fin, rsv1, data)
+}
+
+/**
+ * FormatCloseMessage
+ * @Author：Jack-Z
+ * @Description: 生成关闭帧的payload
+ * @param code
+ * @param text
+ * @return []byte
+ */
+func FormatCloseMessage(code int, text string) []byte {
+	if code == CloseNoStatusReceived {
+		return []byte{}
+	}
+	buf := make([]byte, 2+len(text))
+	binary.BigEndian.PutUint16(buf, uint16(code))
+	copy(buf[2:], text)
+	return buf
+}
+
+// parseCloseMessage 解析关闭帧的payload，没有状态码时返回1005
+func parseCloseMessage(payload []byte) (int, string, error) {
+	if len(payload) == 0 {
+		return CloseNoStatusReceived, "", nil
+	}
+	if len(payload) == 1 {
+		return 0, "", errors.New("websocket: invalid close payload")
+	}
+	code := int(binary.BigEndian.Uint16(payload))
+	text := payload[2:]
+	if !isValidReceivedCloseCode(code) || !utf8.Valid(text) {
+		return 0, "", errors.New("websocket: invalid close code or reason")
+	}
+	return code, string(text), nil
+}
+
+// isValidReceivedCloseCode 可以出现在关闭帧中的状态码，1005、1006、1015只用于本地表示
+func isValidReceivedCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1014:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i&3]
+	}
+}
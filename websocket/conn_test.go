@@ -0,0 +1,184 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// dial 建立客户端连接，extensions为请求的Sec-WebSocket-Extensions
+func dial(t *testing.T, url, extensions string) (*Conn, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if extensions != "" {
+		req.Header.Set("Sec-WebSocket-Extensions", extensions)
+	}
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("unexpected accept key %q", got)
+	}
+	c := newConn(conn, br, false, 8)
+	if resp.Header.Get("Sec-WebSocket-Extensions") != "" {
+		c.compression = true
+		c.writeCompress = true
+	}
+	return c, resp
+}
+
+func echoServer(upgrader *Upgrader) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func TestEcho(t *testing.T) {
+	for _, compression := range []bool{false, true} {
+		srv := echoServer(&Upgrader{EnableCompression: compression})
+		extensions := ""
+		if compression {
+			extensions = "permessage-deflate; client_max_window_bits"
+		}
+		c, resp := dial(t, srv.URL, extensions)
+		if compression != (resp.Header.Get("Sec-WebSocket-Extensions") != "") {
+			t.Fatalf("compression=%v: unexpected extensions %q", compression, resp.Header.Get("Sec-WebSocket-Extensions"))
+		}
+
+		if err := c.WriteJSON(map[string]string{"hello": "world"}); err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]string
+		if err := c.ReadJSON(&got); err != nil || got["hello"] != "world" {
+			t.Fatalf("compression=%v: unexpected echo %v %v", compression, got, err)
+		}
+
+		// 分片发送，分片大小为8字节
+		w, _ := c.NextWriter(BinaryMessage)
+		payload := bytes.Repeat([]byte("fragment"), 10)
+		w.Write(payload)
+		w.Close()
+		if _, data, err := c.ReadMessage(); err != nil || !bytes.Equal(data, payload) {
+			t.Fatalf("compression=%v: unexpected fragmented echo %q %v", compression, data, err)
+		}
+
+		pong := make(chan string, 1)
+		c.SetPongHandler(func(appData string) error {
+			pong <- appData
+			return nil
+		})
+		c.Ping([]byte("ping"))
+		c.WriteMessage(TextMessage, []byte("after ping"))
+		if _, data, err := c.ReadMessage(); err != nil || string(data) != "after ping" {
+			t.Fatalf("unexpected echo %q %v", data, err)
+		}
+		if got := <-pong; got != "ping" {
+			t.Fatalf("unexpected pong %q", got)
+		}
+
+		c.WriteClose(CloseGoingAway, "bye")
+		if _, _, err := c.ReadMessage(); !IsCloseError(err, CloseGoingAway) {
+			t.Fatalf("unexpected close error %v", err)
+		}
+		c.UnderlyingConn().Close()
+		srv.Close()
+	}
+}
+
+func TestProtocolError(t *testing.T) {
+	srv := echoServer(&Upgrader{})
+	defer srv.Close()
+	c, _ := dial(t, srv.URL, "")
+	// 客户端发送不带掩码的帧，服务端应以1002关闭
+	c.isServer = true
+	c.WriteMessage(TextMessage, []byte("unmasked"))
+	c.isServer = false
+	if _, _, err := c.ReadMessage(); !IsCloseError(err, CloseProtocolError) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestHandshakeError(t *testing.T) {
+	srv := echoServer(&Upgrader{})
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+func TestReadLimit(t *testing.T) {
+	srv := echoServer(&Upgrader{ReadLimit: 16, EnableCompression: true})
+	defer srv.Close()
+
+	// 帧头声明了超大的长度，服务端不应按声明的长度分配内存
+	c, _ := dial(t, srv.URL, "")
+	header := []byte{finalBit | BinaryMessage, maskBit | 127, 0, 0, 1, 0, 0, 0, 0, 0, 1, 2, 3, 4}
+	c.UnderlyingConn().Write(header)
+	if _, _, err := c.ReadMessage(); !IsCloseError(err, CloseMessageTooBig) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// 分片累计超出限制
+	c, _ = dial(t, srv.URL, "")
+	w, _ := c.NextWriter(BinaryMessage)
+	w.Write(bytes.Repeat([]byte("x"), 24))
+	w.Close()
+	if _, _, err := c.ReadMessage(); !IsCloseError(err, CloseMessageTooBig) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// 分片之间的控制帧不占用消息的长度限制
+	c, _ = dial(t, srv.URL, "")
+	c.writeFrame(TextMessage, false, false, []byte("0123456789"))
+	c.writeFrame(PingMessage, true, false, []byte("ping-ping!"))
+	c.writeFrame(continuationFrame, true, false, []byte("abcdef"))
+	if _, data, err := c.ReadMessage(); err != nil || string(data) != "0123456789abcdef" {
+		t.Fatalf("unexpected message %q %v", data, err)
+	}
+
+	// 压缩后很小，解压后超出限制
+	bomb := echoServer(&Upgrader{ReadLimit: 64 << 10, EnableCompression: true})
+	defer bomb.Close()
+	c, _ = dial(t, bomb.URL, "permessage-deflate")
+	c.WriteMessage(BinaryMessage, make([]byte, 1<<20))
+	if _, _, err := c.ReadMessage(); !IsCloseError(err, CloseMessageTooBig) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
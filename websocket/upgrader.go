@@ -0,0 +1,210 @@
+package websocket
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+/**
+ * HandshakeError
+ * @Description: 握手失败的错误，此时已经向客户端返回了错误响应
+ */
+type HandshakeError struct {
+	message string
+}
+
+func (e HandshakeError) Error() string {
+	return e.message
+}
+
+/**
+ * Upgrader
+ * @Description: 把http请求升级为websocket连接（RFC 6455），零值可以直接使用
+ */
+type Upgrader struct {
+	HandshakeTimeout  time.Duration // 写握手响应的超时时间，为0时不限制
+	WriteBufferSize   int           // NextWriter的分片大小，为0时使用4096
+	ReadLimit         int64         // 单条消息（解压后）的最大字节数，为0时使用32MB，可以用Conn.SetReadLimit修改
+	Subprotocols      []string      // 服务端支持的子协议，按优先级排序
+	EnableCompression bool          // 是否支持permessage-deflate压缩
+	// CheckOrigin 校验Origin请求头，为nil时只允许没有Origin或Origin与Host一致的请求
+	CheckOrigin func(r *http.Request) bool
+	// Error 握手失败时的响应，为nil时使用http.Error
+	Error func(w http.ResponseWriter, r *http.Request, status int, reason error)
+}
+
+/**
+ * Upgrade
+ * @Author：Jack-Z
+ * @Description: 校验握手请求，接管连接并返回101响应；失败时已经写出错误响应
+ * @receiver u
+ * @param w 需要支持http.Hijacker
+ * @param r
+ * @param responseHeader 额外的响应头，如Set-Cookie
+ * @return *Conn
+ * @return error
+ */
+func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeader http.Header) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		return u.fail(w, r, http.StatusMethodNotAllowed, "websocket: upgrade requires GET")
+	}
+	if !headerContainsToken(r.Header, "Connection", "upgrade") {
+		return u.fail(w, r, http.StatusBadRequest, "websocket: 'upgrade' token not found in 'Connection' header")
+	}
+	if !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		return u.fail(w, r, http.StatusBadRequest, "websocket: 'websocket' token not found in 'Upgrade' header")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return u.fail(w, r, http.StatusUpgradeRequired, "websocket: unsupported version")
+	}
+	checkOrigin := u.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(r) {
+		return u.fail(w, r, http.StatusForbidden, "websocket: request origin not allowed")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return u.fail(w, r, http.StatusBadRequest, "websocket: invalid 'Sec-WebSocket-Key' header")
+	}
+	subprotocol := u.selectSubprotocol(r)
+	var extension string
+	if u.EnableCompression {
+		extension = negotiateCompression(r.Header.Values("Sec-WebSocket-Extensions"))
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return u.fail(w, r, http.StatusInternalServerError, "websocket: response does not implement http.Hijacker")
+	}
+	netConn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return u.fail(w, r, http.StatusInternalServerError, err.Error())
+	}
+
+	var sb strings.Builder
+	sb.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: ")
+	sb.WriteString(computeAcceptKey(key))
+	sb.WriteString("\r\n")
+	if subprotocol != "" {
+		sb.WriteString("Sec-WebSocket-Protocol: " + subprotocol + "\r\n")
+	}
+	if extension != "" {
+		sb.WriteString("Sec-WebSocket-Extensions: " + extension + "\r\n")
+	}
+	for k, values := range responseHeader {
+		if k == "Sec-Websocket-Protocol" || k == "Sec-Websocket-Extensions" {
+			continue
+		}
+		for _, v := range values {
+			sb.WriteString(k + ": " + strings.NewReplacer("\r", "", "\n", "").Replace(v) + "\r\n")
+		}
+	}
+	sb.WriteString("\r\n")
+
+	// 清除http.Server设置的超时，之后由Conn.SetReadDeadline等控制
+	netConn.SetDeadline(time.Time{})
+	if u.HandshakeTimeout > 0 {
+		netConn.SetWriteDeadline(time.Now().Add(u.HandshakeTimeout))
+	}
+	if _, err := netConn.Write([]byte(sb.String())); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	if u.HandshakeTimeout > 0 {
+		netConn.SetWriteDeadline(time.Time{})
+	}
+
+	c := newConn(netConn, brw.Reader, true, u.WriteBufferSize)
+	c.SetReadLimit(u.ReadLimit)
+	c.subprotocol = subprotocol
+	if extension != "" {
+		c.compression = true
+		c.writeCompress = true
+	}
+	return c, nil
+}
+
+func (u *Upgrader) fail(w http.ResponseWriter, r *http.Request, status int, reason string) (*Conn, error) {
+	err := HandshakeError{message: reason}
+	if u.Error != nil {
+		u.Error(w, r, status, err)
+	} else {
+		http.Error(w, http.StatusText(status), status)
+	}
+	return nil, err
+}
+
+// selectSubprotocol 按服务端的优先级选择客户端支持的子协议
+func (u *Upgrader) selectSubprotocol(r *http.Request) string {
+	if len(u.Subprotocols) == 0 {
+		return ""
+	}
+	offered := Subprotocols(r)
+	for _, protocol := range u.Subprotocols {
+		for _, offer := range offered {
+			if offer == protocol {
+				return protocol
+			}
+		}
+	}
+	return ""
+}
+
+// Subprotocols 客户端请求的子协议
+func Subprotocols(r *http.Request) []string {
+	var protocols []string
+	for _, value := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(value, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				protocols = append(protocols, protocol)
+			}
+		}
+	}
+	return protocols
+}
+
+// IsWebSocketUpgrade 请求是否为websocket握手请求
+func IsWebSocketUpgrade(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Connection", "upgrade") &&
+		headerContainsToken(r.Header, "Upgrade", "websocket")
+}
+
+func computeAcceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// sameOrigin 没有Origin，或Origin的host与请求的Host一致
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// headerContainsToken 逗号分隔的请求头中是否包含token，不区分大小写
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
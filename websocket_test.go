@@ -0,0 +1,26 @@
+package go_rookie_test
+
+import (
+	"github.com/Jack-ZL/go_rookie"
+	"github.com/Jack-ZL/go_rookie/gorookietest"
+	"github.com/Jack-ZL/go_rookie/websocket"
+	"net/http"
+	"testing"
+)
+
+func TestUpgradeFailed(t *testing.T) {
+	engine := go_rookie.New()
+	// 自定义的错误响应只写响应体，不设置状态码
+	engine.Upgrader.Error = func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+		w.Write([]byte("handshake failed"))
+	}
+	called := false
+	engine.Group("").WebSocket("/ws", func(ctx *go_rookie.Context, conn *websocket.Conn) {
+		called = true
+	})
+
+	gorookietest.Get(t, engine, "/ws").Do().AssertStatus(http.StatusOK).AssertBody("handshake failed")
+	if called {
+		t.Error("handler called without upgrade")
+	}
+}